@@ -0,0 +1,29 @@
+// calibrate-hash bu makinede hedef gecikmeye ulaşan şifre hash parametrelerini ölçer
+// ve kullanılacak environment değişkenlerini yazdırır.
+//
+//	go run ./cmd/calibrate-hash -target 250ms
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"elk-stack-user/internal/passhash"
+)
+
+func main() {
+	target := flag.Duration("target", 250*time.Millisecond, "target latency for a single hash")
+	memory := flag.Uint("memory", uint(passhash.DefaultArgon2idParams.Memory), "argon2id memory in KiB")
+	parallelism := flag.Uint("parallelism", uint(passhash.DefaultArgon2idParams.Parallelism), "argon2id parallelism")
+	flag.Parse()
+
+	cost, bcryptElapsed := passhash.CalibrateBcrypt(*target)
+	params, argonElapsed := passhash.CalibrateArgon2id(*target, uint32(*memory), uint8(*parallelism))
+
+	fmt.Printf("# bcrypt: %s per hash\n", bcryptElapsed.Round(time.Millisecond))
+	fmt.Printf("PASSWORD_HASH_ALGORITHM=bcrypt\nBCRYPT_COST=%d\n\n", cost)
+	fmt.Printf("# argon2id: %s per hash\n", argonElapsed.Round(time.Millisecond))
+	fmt.Printf("PASSWORD_HASH_ALGORITHM=argon2id\nARGON2_MEMORY_KIB=%d\nARGON2_ITERATIONS=%d\nARGON2_PARALLELISM=%d\n",
+		params.Memory, params.Iterations, params.Parallelism)
+}
@@ -2,13 +2,22 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 	"elk-stack-user/internal/database"
+	"elk-stack-user/internal/ipacl"
+	"elk-stack-user/internal/mailer"
+	"elk-stack-user/internal/oidc"
+	"elk-stack-user/internal/passhash"
+	"elk-stack-user/internal/repository"
 	"elk-stack-user/internal/router"
+	"elk-stack-user/internal/scheduler"
+	"elk-stack-user/internal/service"
 	"elk-stack-user/internal/logger"
 )
 
@@ -47,8 +56,98 @@ func main() {
 		logger.Logger.Fatal("Failed to migrate database", logger.Error(err))
 	}
 
+	// Mailer
+	mail, err := mailer.New(mailer.NewConfig())
+	if err != nil {
+		logger.Logger.Fatal("Failed to initialize mailer", logger.Error(err))
+	}
+
+	// Password hasher
+	hasherConfig := passhash.NewConfig()
+	hasher, err := passhash.New(hasherConfig)
+	if err != nil {
+		logger.Logger.Fatal("Failed to initialize password hasher", logger.Error(err))
+	}
+
+	// Service configuration
+	serviceConfig := service.NewConfig()
+	serviceConfig.PasswordHasher = hasher
+	logger.Logger.Info("Password hashing configured",
+		logger.String("algorithm", hasherConfig.Algorithm),
+	)
+	if path := serviceConfig.BreachedPasswordsFile; path != "" {
+		breached, err := service.LoadBreachedPasswordList(path)
+		if err != nil {
+			logger.Logger.Fatal("Failed to load breached password list", logger.Error(err))
+		}
+		serviceConfig.PasswordPolicy.Breached = breached
+		logger.Logger.Info("Breached password list loaded",
+			logger.String("path", path),
+			logger.Int("hashes", breached.Len()),
+		)
+	}
+
+	// Background maintenance jobs
+	schedulerConfig := scheduler.NewConfig()
+	var jobs *scheduler.Scheduler
+	if schedulerConfig.Enabled {
+		// Ban escalation geçmişi silinmesin
+		if schedulerConfig.BanRetention < serviceConfig.BanPolicy.EscalationWindow {
+			schedulerConfig.BanRetention = serviceConfig.BanPolicy.EscalationWindow
+		}
+
+		sqlDB, err := db.DB()
+		if err != nil {
+			logger.Logger.Fatal("Failed to get database handle", logger.Error(err))
+		}
+		jobs = scheduler.New(scheduler.NewPostgresLocker(sqlDB))
+		if err := scheduler.RegisterMaintenanceJobs(jobs, schedulerConfig, repository.NewUserRepository(db)); err != nil {
+			logger.Logger.Fatal("Failed to register scheduled jobs", logger.Error(err))
+		}
+		jobs.Start(context.Background())
+	}
+
+	// List cursor signing secret
+	if serviceConfig.CursorSecret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			logger.Logger.Fatal("Failed to generate cursor secret", logger.Error(err))
+		}
+		serviceConfig.CursorSecret = hex.EncodeToString(secret)
+		logger.Logger.Warn("CURSOR_SECRET not set - using a random secret, pagination cursors will not survive a restart or work across instances")
+	}
+
+	// OIDC ID token signing key
+	if path := serviceConfig.OIDCSigningKeyFile; path != "" {
+		serviceConfig.OIDCSigner, err = oidc.LoadSigner(path)
+		if err != nil {
+			logger.Logger.Fatal("Failed to load OIDC signing key", logger.Error(err))
+		}
+	} else {
+		serviceConfig.OIDCSigner, err = oidc.GenerateSigner()
+		if err != nil {
+			logger.Logger.Fatal("Failed to generate OIDC signing key", logger.Error(err))
+		}
+		logger.Logger.Warn("OIDC_SIGNING_KEY_FILE not set - using an ephemeral signing key, ID tokens will not verify after restart")
+	}
+	logger.Logger.Info("OIDC provider configured",
+		logger.String("issuer", serviceConfig.OIDCIssuer),
+		logger.String("kid", serviceConfig.OIDCSigner.KeyID()),
+	)
+
+	// Forgot/resend/kayıt mailleri istek dışında sınırlı bir kuyrukta gönderilir
+	serviceConfig.MailQueue = service.NewMailQueue(serviceConfig.MailQueueWorkers, serviceConfig.MailQueueSize, time.Minute)
+
+	// IP access control lists
+	acl, err := ipacl.New(ipacl.NewConfig(), repository.NewIPRuleRepository(db))
+	if err != nil {
+		logger.Logger.Fatal("Invalid IP access control configuration", logger.Error(err))
+	}
+	acl.Start(context.Background())
+	defer acl.Stop()
+
 	// Setup router
-	r := router.SetupRouter(db)
+	r := router.SetupRouter(db, mail, serviceConfig, acl)
 
 	// Server configuration
 	port := getEnv("PORT", "8080")
@@ -83,6 +182,15 @@ func main() {
 		logger.Logger.Fatal("Server forced to shutdown", logger.Error(err))
 	}
 
+	if jobs != nil {
+		jobs.Stop()
+	}
+
+	// Kabul edilmiş mailler gönderilmeden çıkılmaz
+	if err := serviceConfig.MailQueue.Stop(ctx); err != nil {
+		logger.Logger.Error("Mail queue did not drain before shutdown", logger.Error(err))
+	}
+
 	logger.Logger.Info("Server exited")
 }
 
@@ -0,0 +1,116 @@
+// Package apperror service ve repository katmanlarının döndürdüğü tipli hataları tanımlar.
+// Her hata bir Kind taşır; HTTP katmanı status kodunu sadece Kind'a bakarak seçer.
+package apperror
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Hata türleri. errors.Is(err, apperror.ErrNotFound) ile kontrol edilir.
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrValidation   = errors.New("validation failed")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrBanned       = errors.New("banned")
+	ErrRateLimited  = errors.New("too many requests")
+	// ErrPreconditionFailed If-Match gibi koşullu isteklerin koşulu sağlanmadığında
+	ErrPreconditionFailed = errors.New("precondition failed")
+	// ErrUnsupportedMediaType isteğin Content-Type'ı endpoint tarafından desteklenmiyor
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+)
+
+// Error client'a gösterilebilir mesajı, türü ve opsiyonel sebebi taşır
+type Error struct {
+	Kind    error
+	Message string
+	Err     error
+	// Fields alan bazlı validation hataları, yanıtta "errors" olarak döner
+	Fields []FieldError
+}
+
+// FieldError tek bir request alanının hangi kuralı geçemediği
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+func New(kind error, message string) *Error {
+	return &Error{Kind: kind, Message: message}
+}
+
+// Wrap altta yatan hatayı (ör. gorm.ErrRecordNotFound) errors.Is için zincirde tutar
+func Wrap(kind error, message string, err error) *Error {
+	return &Error{Kind: kind, Message: message, Err: err}
+}
+
+// InvalidFields alan hatalarıyla birlikte validation hatası oluşturur
+func InvalidFields(message string, fields []FieldError, err error) *Error {
+	return &Error{Kind: ErrValidation, Message: message, Err: err, Fields: fields}
+}
+
+func NotFound(message string) *Error     { return New(ErrNotFound, message) }
+func Conflict(message string) *Error     { return New(ErrConflict, message) }
+func Validation(message string) *Error   { return New(ErrValidation, message) }
+func Unauthorized(message string) *Error { return New(ErrUnauthorized, message) }
+func Forbidden(message string) *Error    { return New(ErrForbidden, message) }
+func Banned(message string) *Error       { return New(ErrBanned, message) }
+func RateLimited(message string) *Error  { return New(ErrRateLimited, message) }
+func PreconditionFailed(message string) *Error {
+	return New(ErrPreconditionFailed, message)
+}
+func UnsupportedMediaType(message string) *Error {
+	return New(ErrUnsupportedMediaType, message)
+}
+
+// Detailer yanıta ek alan koyan hatalar (ör. şifre politikası ihlalleri, ban bitişi)
+type Detailer interface {
+	Details() map[string]interface{}
+}
+
+// Retrier Retry-After header'ı gerektiren hatalar
+type Retrier interface {
+	RetryAfter(now time.Time) int64
+}
+
+// statusByKind hata türlerinin HTTP karşılıkları
+var statusByKind = map[error]int{
+	ErrNotFound:     http.StatusNotFound,
+	ErrConflict:     http.StatusConflict,
+	ErrValidation:   http.StatusBadRequest,
+	ErrUnauthorized: http.StatusUnauthorized,
+	ErrForbidden:    http.StatusForbidden,
+	ErrBanned:       http.StatusLocked,
+	ErrRateLimited:  http.StatusTooManyRequests,
+
+	ErrPreconditionFailed:   http.StatusPreconditionFailed,
+	ErrUnsupportedMediaType: http.StatusUnsupportedMediaType,
+}
+
+// Status zincirdeki en dıştaki *Error'un türüne göre HTTP status kodunu döner,
+// tipli olmayan hatalar 500'dür
+func Status(err error) int {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		if status, ok := statusByKind[appErr.Kind]; ok {
+			return status
+		}
+	}
+	return http.StatusInternalServerError
+}
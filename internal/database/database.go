@@ -82,10 +82,13 @@ func Connect(config *Config) (*gorm.DB, error) {
 func AutoMigrate(db *gorm.DB) error {
 	logger.Logger.Info("Starting database migration...")
 	
-	err := db.AutoMigrate(&model.User{}, &model.LoginAttempt{}, &model.BanRecord{})
+	err := db.AutoMigrate(&model.User{}, &model.LoginAttempt{}, &model.BanRecord{}, &model.Session{}, &model.UserToken{}, &model.RecoveryCode{}, &model.IPRule{}, &model.APIKey{}, &model.OAuthClient{}, &model.OAuthAuthorizationCode{}, &model.OAuthAccessToken{})
 	if err != nil {
 		return fmt.Errorf("failed to migrate database: %w", err)
 	}
+	if err := runMigrations(db); err != nil {
+		return fmt.Errorf("failed to apply SQL migrations: %w", err)
+	}
 
 	logger.Logger.Info("Database migration completed successfully")
 	return nil
@@ -0,0 +1,119 @@
+package database
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"elk-stack-user/internal/logger"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// migrationFiles GORM AutoMigrate'in ifade edemediği şema değişiklikleri
+// (extension, generated column, GIN/partial index). Dosya adı "<version>_<name>.sql".
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLockKey birden fazla instance aynı anda açılırsa migration'ları sıraya sokar
+const migrationLockKey = 7310452331
+
+type migration struct {
+	Version int64
+	Name    string
+	SQL     string
+}
+
+// SchemaMigration uygulanmış SQL migration kaydı
+type SchemaMigration struct {
+	Version   int64     `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"not null"`
+	AppliedAt time.Time `gorm:"autoCreateTime"`
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+func loadMigrations() ([]migration, error) {
+	entries, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]migration, 0, len(entries))
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".sql")
+		prefix, rest, ok := strings.Cut(name, "_")
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if !ok || err != nil {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+		content, err := migrationFiles.ReadFile(path.Join("migrations", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, migration{Version: version, Name: rest, SQL: string(content)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].Version == migrations[i-1].Version {
+			return nil, fmt.Errorf("duplicate migration version %d", migrations[i].Version)
+		}
+	}
+	return migrations, nil
+}
+
+// runMigrations uygulanmamış SQL migration'ları sırayla, her birini kendi transaction'ında çalıştırır
+func runMigrations(db *gorm.DB) error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", migrationLockKey).Error; err != nil {
+				return err
+			}
+			var applied int64
+			if err := tx.Model(&SchemaMigration{}).Where("version = ?", m.Version).Count(&applied).Error; err != nil {
+				return err
+			}
+			if applied > 0 {
+				return nil
+			}
+
+			if err := tx.Exec(m.SQL).Error; err != nil {
+				return err
+			}
+			if err := tx.Create(&SchemaMigration{Version: m.Version, Name: m.Name}).Error; err != nil {
+				return err
+			}
+			logger.Logger.Info("Applied SQL migration",
+				logger.Int64("version", m.Version),
+				logger.String("name", m.Name),
+			)
+			return nil
+		})
+		if err != nil {
+			// RAISE ile verilen rapor DETAIL/HINT'te gelir, PgError.Error() bunları içermez
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Detail != "" {
+				return fmt.Errorf("migration %d_%s failed: %w\n%s\n%s", m.Version, m.Name, err, pgErr.Detail, pgErr.Hint)
+			}
+			return fmt.Errorf("migration %d_%s failed: %w", m.Version, m.Name, err)
+		}
+	}
+	return nil
+}
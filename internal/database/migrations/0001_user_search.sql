@@ -0,0 +1,19 @@
+-- Full-text and trigram search over users (GET /users/search)
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- 'simple' config: names and usernames are not stemmed or stop-word filtered.
+-- The email local part is split on common separators so "john.doe" matches "doe".
+ALTER TABLE users ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(username, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(first_name, '') || ' ' || coalesce(last_name, '')), 'B') ||
+        setweight(to_tsvector('simple', translate(split_part(coalesce(email, ''), '@', 1), '._-+', '    ')), 'C')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_users_search_vector ON users USING GIN (search_vector);
+
+CREATE INDEX IF NOT EXISTS idx_users_username_trgm ON users USING GIN (username gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_users_first_name_trgm ON users USING GIN (first_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_users_last_name_trgm ON users USING GIN (last_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_users_email_local_trgm ON users USING GIN ((split_part(email, '@', 1)) gin_trgm_ops);
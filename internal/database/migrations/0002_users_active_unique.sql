@@ -0,0 +1,8 @@
+-- Soft-deleted users no longer block reuse of their username or email.
+-- Replaces the unique indexes GORM created from the old uniqueIndex tags.
+
+DROP INDEX IF EXISTS idx_users_username;
+DROP INDEX IF EXISTS idx_users_email;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username_active ON users (username) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_active ON users (email) WHERE deleted_at IS NULL;
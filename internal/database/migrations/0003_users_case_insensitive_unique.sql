@@ -0,0 +1,33 @@
+-- Usernames and emails become unique regardless of case.
+-- Existing accounts that differ only in case must be merged or renamed first;
+-- the migration refuses to continue and lists them instead of silently picking one.
+
+DO $$
+DECLARE
+    report text;
+BEGIN
+    SELECT string_agg(format('%s %L: user ids %s', kind, value, ids), E'\n' ORDER BY kind, value)
+    INTO report
+    FROM (
+        SELECT 'username' AS kind, lower(username) AS value, string_agg(id::text, ', ' ORDER BY id) AS ids
+        FROM users WHERE deleted_at IS NULL
+        GROUP BY lower(username) HAVING count(*) > 1
+        UNION ALL
+        SELECT 'email', lower(email), string_agg(id::text, ', ' ORDER BY id)
+        FROM users WHERE deleted_at IS NULL
+        GROUP BY lower(email) HAVING count(*) > 1
+    ) collisions;
+
+    IF report IS NOT NULL THEN
+        RAISE EXCEPTION 'case-insensitive username/email collisions must be resolved before this migration can run'
+            USING DETAIL = report,
+                  HINT = 'Rename or soft-delete all but one account per value, then restart the service.';
+    END IF;
+END
+$$;
+
+DROP INDEX IF EXISTS idx_users_username_active;
+DROP INDEX IF EXISTS idx_users_email_active;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username_lower ON users (lower(username)) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_lower ON users (lower(email)) WHERE deleted_at IS NULL;
@@ -0,0 +1,8 @@
+-- One row per scheduled job. An instance may run a job's tick only while holding the job's
+-- advisory lock and only if leased_until is not after the tick; it then moves leased_until to
+-- the job's next scheduled time, so instances woken later by jitter skip the same tick.
+
+CREATE TABLE IF NOT EXISTS scheduler_leases (
+    job          text PRIMARY KEY,
+    leased_until timestamptz NOT NULL
+);
@@ -0,0 +1,51 @@
+-- Usernames and emails created before normalization was added are rewritten to the form
+-- the service now stores: NFKC, trimmed, email domain in lowercase. Lookups NFKC-normalize
+-- the input, so without this backfill such accounts could no longer log in by a
+-- full-width or otherwise non-canonical spelling of their name.
+-- Postgres cannot convert non-ASCII domains to punycode, and normalization can make two
+-- accounts collide; like 0003, the migration lists those accounts instead of guessing.
+-- normalize() requires a UTF8 database.
+
+DO $$
+DECLARE
+    report text;
+BEGIN
+    CREATE TEMP TABLE users_nfkc ON COMMIT DROP AS
+    SELECT id, deleted_at,
+           btrim(normalize(username, NFKC), E' \t\n\r\f\v') AS username,
+           btrim(normalize(email, NFKC), E' \t\n\r\f\v') AS email
+    FROM users;
+
+    UPDATE users_nfkc
+    SET email = substring(email from '^(.*@)') || lower(substring(email from '@([^@]*)$'))
+    WHERE email LIKE '%_@_%';
+
+    SELECT string_agg(format('%s %L: user ids %s', kind, value, ids), E'\n' ORDER BY kind, value)
+    INTO report
+    FROM (
+        SELECT 'username' AS kind, lower(username) AS value, string_agg(id::text, ', ' ORDER BY id) AS ids
+        FROM users_nfkc WHERE deleted_at IS NULL
+        GROUP BY lower(username) HAVING count(*) > 1
+        UNION ALL
+        SELECT 'email', lower(email), string_agg(id::text, ', ' ORDER BY id)
+        FROM users_nfkc WHERE deleted_at IS NULL
+        GROUP BY lower(email) HAVING count(*) > 1
+        UNION ALL
+        SELECT 'non-ASCII email domain', email, id::text
+        FROM users_nfkc WHERE deleted_at IS NULL
+          AND substring(email from '@([^@]*)$') ~ '[^[:ascii:]]'
+    ) problems;
+
+    IF report IS NOT NULL THEN
+        RAISE EXCEPTION 'usernames/emails that cannot be normalized automatically must be fixed before this migration can run'
+            USING DETAIL = report,
+                  HINT = 'Rename or soft-delete colliding accounts and rewrite non-ASCII email domains in punycode (e.g. with idn2), then restart the service.';
+    END IF;
+
+    UPDATE users u
+    SET username = n.username, email = n.email
+    FROM users_nfkc n
+    WHERE u.id = n.id
+      AND (u.username IS DISTINCT FROM n.username OR u.email IS DISTINCT FROM n.email);
+END
+$$;
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"elk-stack-user/internal/apperror"
+	"elk-stack-user/internal/middleware"
+	"elk-stack-user/internal/model"
+	"elk-stack-user/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyHandler struct {
+	apiKeyService service.APIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: apiKeyService}
+}
+
+// ListAPIKeys godoc
+// @Summary List API keys
+// @Description List all API keys without their secrets (admin only)
+// @Tags admin
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /admin/api-keys [get]
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	keys, err := h.apiKeyService.ListKeys(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"api_keys": keys})
+}
+
+// CreateAPIKey godoc
+// @Summary Create API key
+// @Description Create an API key for a service. The key is only returned once (admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param key body model.CreateAPIKeyRequest true "API key"
+// @Success 201 {object} model.APIKeyResponse
+// @Failure 400 {object} map[string]interface{}
+// @Router /admin/api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	var req model.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalidRequest(c, err)
+		return
+	}
+
+	key, err := h.apiKeyService.CreateKey(c.Request.Context(), c.GetUint(middleware.ContextUserID), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, key)
+}
+
+// RotateAPIKey godoc
+// @Summary Rotate API key
+// @Description Issue a new key for an existing API key; the old key stops working immediately (admin only)
+// @Tags admin
+// @Produce json
+// @Param id path int true "API key ID"
+// @Success 200 {object} model.APIKeyResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/api-keys/{id}/rotate [post]
+func (h *APIKeyHandler) RotateAPIKey(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperror.Validation("Invalid API key ID"))
+		return
+	}
+
+	key, err := h.apiKeyService.RotateKey(c.Request.Context(), c.GetUint(middleware.ContextUserID), uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, key)
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke API key
+// @Description Revoke an API key (admin only)
+// @Tags admin
+// @Param id path int true "API key ID"
+// @Success 204
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperror.Validation("Invalid API key ID"))
+		return
+	}
+
+	if err := h.apiKeyService.RevokeKey(c.Request.Context(), c.GetUint(middleware.ContextUserID), uint(id)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
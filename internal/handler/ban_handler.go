@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"elk-stack-user/internal/apperror"
+	"elk-stack-user/internal/middleware"
+	"elk-stack-user/internal/model"
+	"elk-stack-user/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type BanHandler struct {
+	banService service.BanService
+}
+
+func NewBanHandler(banService service.BanService) *BanHandler {
+	return &BanHandler{banService: banService}
+}
+
+// ListBans godoc
+// @Summary List bans
+// @Description List active bans (admin only). Set include_inactive=true to include expired and lifted bans
+// @Tags admin
+// @Produce json
+// @Param type query string false "Ban type (username, ip, username_ip)"
+// @Param username query string false "Exact username"
+// @Param ip query string false "Exact IP address"
+// @Param cidr query string false "CIDR range, e.g. 10.0.0.0/8"
+// @Param reason query string false "Substring of the ban reason"
+// @Param include_inactive query bool false "Include expired and lifted bans"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /admin/bans [get]
+func (h *BanHandler) ListBans(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil {
+		page = 1
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if err != nil {
+		pageSize = 20
+	}
+	includeInactive, _ := strconv.ParseBool(c.Query("include_inactive"))
+
+	filter := &model.BanFilter{
+		Type:            c.Query("type"),
+		Username:        c.Query("username"),
+		IP:              c.Query("ip"),
+		CIDR:            c.Query("cidr"),
+		Reason:          c.Query("reason"),
+		IncludeInactive: includeInactive,
+	}
+
+	bans, total, err := h.banService.ListBans(c.Request.Context(), filter, page, pageSize)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"bans": bans,
+		"pagination": gin.H{
+			"page":      page,
+			"page_size": pageSize,
+			"total":     total,
+		},
+	})
+}
+
+// GetBan godoc
+// @Summary Get ban
+// @Description Get a ban with the failed login attempts that triggered it (admin only)
+// @Tags admin
+// @Produce json
+// @Param id path int true "Ban ID"
+// @Success 200 {object} model.BanDetailResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/bans/{id} [get]
+func (h *BanHandler) GetBan(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperror.Validation("Invalid ban ID"))
+		return
+	}
+
+	detail, err := h.banService.GetBan(c.Request.Context(), uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, detail)
+}
+
+// CreateBan godoc
+// @Summary Create ban
+// @Description Manually ban a username, an IP or a username/IP pair (admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param ban body model.CreateBanRequest true "Ban"
+// @Success 201 {object} model.BanRecord
+// @Failure 400 {object} map[string]interface{}
+// @Router /admin/bans [post]
+func (h *BanHandler) CreateBan(c *gin.Context) {
+	var req model.CreateBanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalidRequest(c, err)
+		return
+	}
+
+	ban, err := h.banService.CreateBan(c.Request.Context(), c.GetUint(middleware.ContextUserID), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, ban)
+}
+
+// LiftBan godoc
+// @Summary Lift ban
+// @Description Lift an active ban before it expires (admin only)
+// @Tags admin
+// @Param id path int true "Ban ID"
+// @Success 204
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/bans/{id} [delete]
+func (h *BanHandler) LiftBan(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperror.Validation("Invalid ban ID"))
+		return
+	}
+
+	if err := h.banService.LiftBan(c.Request.Context(), c.GetUint(middleware.ContextUserID), uint(id)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"elk-stack-user/internal/apperror"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// UseJSONFieldNames validator hatalarında Go alan adı yerine json tag'ini kullandırır,
+// böylece "errors" dizisi client'ın gönderdiği alan adlarını gösterir
+func UseJSONFieldNames() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+}
+
+// invalidRequest bind veya query parse hatasını ErrorHandler için validation hatası olarak bırakır
+func invalidRequest(c *gin.Context, err error) {
+	c.Error(bindError(err))
+}
+
+// bindError decode ve validator hatalarını validation hatasına çevirir.
+// Validator ve JSON tip hataları alan bazında "errors" listesine çevrilir.
+func bindError(err error) *apperror.Error {
+	var (
+		validationErrs validator.ValidationErrors
+		typeErr        *json.UnmarshalTypeError
+		syntaxErr      *json.SyntaxError
+	)
+	switch {
+	case errors.As(err, &validationErrs):
+		fields := make([]apperror.FieldError, len(validationErrs))
+		for i, fe := range validationErrs {
+			fields[i] = apperror.FieldError{
+				Field:   fieldPath(fe),
+				Rule:    fe.Tag(),
+				Message: ruleMessage(fe),
+			}
+		}
+		return apperror.InvalidFields("request validation failed", fields, err)
+	case errors.As(err, &typeErr):
+		return apperror.InvalidFields("request validation failed", []apperror.FieldError{{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Message: fmt.Sprintf("must be of type %s", typeErr.Type),
+		}}, err)
+	case errors.Is(err, io.EOF):
+		return apperror.Wrap(apperror.ErrValidation, "request body is required", err)
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return apperror.Wrap(apperror.ErrValidation, "request body is not valid JSON", err)
+	default:
+		return apperror.Wrap(apperror.ErrValidation, err.Error(), err)
+	}
+}
+
+// fieldPath struct adını atıp iç içe alanları nokta ile birleştirir (ör. "address.city")
+func fieldPath(fe validator.FieldError) string {
+	_, path, ok := strings.Cut(fe.Namespace(), ".")
+	if !ok {
+		return fe.Field()
+	}
+	return path
+}
+
+func ruleMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "required_without":
+		return fmt.Sprintf("is required when %s is not provided", fe.Param())
+	case "email":
+		return "must be a valid email address"
+	case "min":
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "max":
+		return fmt.Sprintf("must be at most %s", fe.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", fe.Param())
+	default:
+		return fmt.Sprintf("failed the %q rule", fe.Tag())
+	}
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"elk-stack-user/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+// userETag kullanıcı temsilinin strong ETag'i. Version users satırına yapılan her
+// yazmada arttığı için aynı version aynı JSON'u üretir.
+func userETag(version uint) string {
+	return `"` + strconv.FormatUint(uint64(version), 10) + `"`
+}
+
+// parseETags virgülle ayrılmış entity-tag listesini ayırır
+func parseETags(header string) []string {
+	var tags []string
+	for _, tag := range strings.Split(header, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// parseIfMatch If-Match başlığını Precondition'a çevirir. Başlık yoksa veya "*" ise
+// koşulsuz yazılır (kullanıcının var olması yeterlidir). If-Match strong karşılaştırma
+// kullanır; weak ve tanınmayan tag'ler hiçbir versiyonla eşleşmez.
+func parseIfMatch(c *gin.Context) *model.Precondition {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return nil
+	}
+
+	precondition := &model.Precondition{}
+	for _, tag := range parseETags(header) {
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
+		if version, err := strconv.ParseUint(tag[1:len(tag)-1], 10, 32); err == nil {
+			precondition.Versions = append(precondition.Versions, uint(version))
+		}
+	}
+	return precondition
+}
+
+// notModified If-None-Match güncel ETag'i içeriyorsa 304 yazar. If-None-Match weak
+// karşılaştırma kullanır, W/ önekli tag'ler de eşleşir.
+func notModified(c *gin.Context, etag string) bool {
+	header := strings.TrimSpace(c.GetHeader("If-None-Match"))
+	if header == "" {
+		return false
+	}
+
+	for _, tag := range parseETags(header) {
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
+
+// writeUser kullanıcıyı ETag başlığıyla birlikte yazar
+func writeUser(c *gin.Context, status int, user *model.UserResponse) {
+	c.Header("ETag", userETag(user.Version))
+	c.JSON(status, user)
+}
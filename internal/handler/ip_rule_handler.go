@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"elk-stack-user/internal/apperror"
+	"elk-stack-user/internal/middleware"
+	"elk-stack-user/internal/model"
+	"elk-stack-user/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type IPRuleHandler struct {
+	ipRuleService service.IPRuleService
+}
+
+func NewIPRuleHandler(ipRuleService service.IPRuleService) *IPRuleHandler {
+	return &IPRuleHandler{ipRuleService: ipRuleService}
+}
+
+// ListIPRules godoc
+// @Summary List IP rules
+// @Description List active database-backed IP allow/deny rules (admin only). Static env rules are not included
+// @Tags admin
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /admin/ip-rules [get]
+func (h *IPRuleHandler) ListIPRules(c *gin.Context) {
+	rules, err := h.ipRuleService.ListRules(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rules": rules})
+}
+
+// CreateIPRule godoc
+// @Summary Create IP rule
+// @Description Add a CIDR to the deny list (all routes) or the admin allow list (admin only).
+// @Description Allow rules that do not cover the caller's IP are rejected, since they would lock the caller out.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param rule body model.CreateIPRuleRequest true "IP rule"
+// @Success 201 {object} model.IPRule
+// @Failure 400 {object} map[string]interface{}
+// @Router /admin/ip-rules [post]
+func (h *IPRuleHandler) CreateIPRule(c *gin.Context) {
+	var req model.CreateIPRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalidRequest(c, err)
+		return
+	}
+
+	rule, err := h.ipRuleService.CreateRule(c.Request.Context(), c.GetUint(middleware.ContextUserID), c.ClientIP(), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// DeleteIPRule godoc
+// @Summary Delete IP rule
+// @Description Delete a database-backed IP rule (admin only)
+// @Tags admin
+// @Param id path int true "Rule ID"
+// @Success 204
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/ip-rules/{id} [delete]
+func (h *IPRuleHandler) DeleteIPRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperror.Validation("Invalid rule ID"))
+		return
+	}
+
+	if err := h.ipRuleService.DeleteRule(c.Request.Context(), c.GetUint(middleware.ContextUserID), uint(id)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
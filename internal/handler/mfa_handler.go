@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"elk-stack-user/internal/apperror"
+	"elk-stack-user/internal/logger"
+	"elk-stack-user/internal/middleware"
+	"elk-stack-user/internal/model"
+	"elk-stack-user/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// LoginMFA godoc
+// @Summary Complete login with a second factor
+// @Description Exchange the MFA token from /login and a TOTP or recovery code for a session token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param credentials body model.MFALoginRequest true "MFA token and code"
+// @Success 200 {object} model.LoginResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 423 {object} map[string]interface{}
+// @Router /login/mfa [post]
+func (h *UserHandler) LoginMFA(c *gin.Context) {
+	start := time.Now()
+	requestID := c.GetString("request_id")
+
+	var req model.MFALoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalidRequest(c, err)
+		return
+	}
+
+	response, err := h.userService.CompleteMFALogin(c.Request.Context(), &req, c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		latency := time.Since(start)
+		switch {
+		case errors.Is(err, service.ErrInvalidMFAToken), errors.Is(err, service.ErrInvalidMFACode):
+			logger.Logger.Warn("MFA login failed",
+				logger.RequestID(requestID),
+				logger.SecurityEvent("mfa_failed"),
+				logger.String("ip", c.ClientIP()),
+				logger.String("error", err.Error()),
+				logger.ResponseTime(latency),
+			)
+		case errors.Is(err, service.ErrAccountBanned):
+			logger.Logger.Warn("MFA login blocked - account banned",
+				logger.RequestID(requestID),
+				logger.String("ip", c.ClientIP()),
+				logger.ResponseTime(latency),
+			)
+		default:
+			logger.Logger.Error("MFA login failed - unexpected error",
+				logger.RequestID(requestID),
+				logger.String("ip", c.ClientIP()),
+				logger.Error(err),
+				logger.ResponseTime(latency),
+			)
+		}
+		c.Error(err)
+		return
+	}
+
+	logger.Logger.Info("Login successful",
+		logger.RequestID(requestID),
+		logger.UserID(response.User.ID),
+		logger.String("username", response.User.Username),
+		logger.String("ip", c.ClientIP()),
+		logger.Bool("mfa", true),
+		logger.ResponseTime(time.Since(start)),
+		logger.StatusCode(http.StatusOK),
+	)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// EnrollTOTP godoc
+// @Summary Start TOTP enrollment
+// @Description Generate a new TOTP secret. 2FA is enabled only after the first code is confirmed.
+// @Tags mfa
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.TOTPEnrollmentResponse
+// @Failure 409 {object} map[string]interface{}
+// @Router /me/mfa/totp [post]
+func (h *UserHandler) EnrollTOTP(c *gin.Context) {
+	userID := c.GetUint(middleware.ContextUserID)
+
+	enrollment, err := h.userService.EnrollTOTP(c.Request.Context(), userID)
+	if err != nil {
+		h.mfaError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// TOTPQRCode godoc
+// @Summary QR code of pending TOTP enrollment
+// @Tags mfa
+// @Produce png
+// @Security BearerAuth
+// @Success 200 {file} binary
+// @Failure 404 {object} map[string]interface{}
+// @Router /me/mfa/totp/qr.png [get]
+func (h *UserHandler) TOTPQRCode(c *gin.Context) {
+	userID := c.GetUint(middleware.ContextUserID)
+
+	png, err := h.userService.TOTPQRCode(c.Request.Context(), userID)
+	if err != nil {
+		h.mfaError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "image/png", png)
+}
+
+// ConfirmTOTP godoc
+// @Summary Confirm TOTP enrollment
+// @Description Enable 2FA with the first code from the authenticator app and return one-time recovery codes
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.TOTPConfirmRequest true "TOTP code"
+// @Success 200 {object} model.TOTPConfirmResponse
+// @Failure 400 {object} map[string]interface{}
+// @Router /me/mfa/totp/confirm [post]
+func (h *UserHandler) ConfirmTOTP(c *gin.Context) {
+	requestID := c.GetString("request_id")
+	userID := c.GetUint(middleware.ContextUserID)
+
+	var req model.TOTPConfirmRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalidRequest(c, err)
+		return
+	}
+
+	codes, err := h.userService.ConfirmTOTP(c.Request.Context(), userID, req.Code)
+	if err != nil {
+		h.mfaError(c, err)
+		return
+	}
+
+	logger.Logger.Info("Two-factor authentication enabled",
+		logger.RequestID(requestID),
+		logger.SecurityEvent("mfa_enabled"),
+		logger.UserID(userID),
+		logger.String("ip", c.ClientIP()),
+	)
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, model.TOTPConfirmResponse{RecoveryCodes: codes})
+}
+
+// DisableTOTP godoc
+// @Summary Disable TOTP
+// @Description Disable 2FA after re-entering the account password
+// @Tags mfa
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.TOTPDisableRequest true "Current password"
+// @Success 204 "No Content"
+// @Failure 403 {object} map[string]interface{}
+// @Router /me/mfa/totp/disable [post]
+func (h *UserHandler) DisableTOTP(c *gin.Context) {
+	requestID := c.GetString("request_id")
+	userID := c.GetUint(middleware.ContextUserID)
+
+	var req model.TOTPDisableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalidRequest(c, err)
+		return
+	}
+
+	if err := h.userService.DisableTOTP(c.Request.Context(), userID, req.Password); err != nil {
+		h.mfaError(c, err)
+		return
+	}
+
+	logger.Logger.Info("Two-factor authentication disabled",
+		logger.RequestID(requestID),
+		logger.SecurityEvent("mfa_disabled"),
+		logger.UserID(userID),
+		logger.String("ip", c.ClientIP()),
+	)
+
+	c.Status(http.StatusNoContent)
+}
+
+// mfaError MFA yönetim endpoint'lerindeki hataları ErrorHandler'a bırakır.
+// Burada yanlış kod bir oturum hatası değil, girdi hatasıdır (400).
+func (h *UserHandler) mfaError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrInvalidMFACode) {
+		err = apperror.Wrap(apperror.ErrValidation, err.Error(), err)
+	}
+	c.Error(err)
+}
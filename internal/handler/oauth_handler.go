@@ -0,0 +1,434 @@
+package handler
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"elk-stack-user/internal/apperror"
+	"elk-stack-user/internal/logger"
+	"elk-stack-user/internal/middleware"
+	"elk-stack-user/internal/model"
+	"elk-stack-user/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type OAuthHandler struct {
+	oauthService service.OAuthService
+}
+
+func NewOAuthHandler(oauthService service.OAuthService) *OAuthHandler {
+	return &OAuthHandler{oauthService: oauthService}
+}
+
+// loginPage authorize akışındaki login ve MFA formu. Authorize parametreleri hidden field olarak taşınır.
+var loginPage = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Sign in</title></head>
+<body>
+<h1>Sign in</h1>
+{{if .Error}}<p role="alert">{{.Error}}</p>{{end}}
+<form method="post" action="/oauth/authorize">
+{{range $name, $value := .Params}}<input type="hidden" name="{{$name}}" value="{{$value}}">
+{{end}}{{if .MFAToken}}<input type="hidden" name="mfa_token" value="{{.MFAToken}}">
+<label>Authentication code <input name="code" autocomplete="one-time-code" autofocus></label>
+<label>or recovery code <input name="recovery_code"></label>
+{{else}}<label>Username or email <input name="username" autocomplete="username" autofocus></label>
+<label>Password <input type="password" name="password" autocomplete="current-password"></label>
+{{end}}<button type="submit">Continue</button>
+</form>
+</body>
+</html>`))
+
+type loginPageData struct {
+	Params   map[string]string
+	MFAToken string
+	Error    string
+}
+
+// Authorize godoc
+// @Summary OAuth2 authorization endpoint
+// @Description Start the authorization code flow (PKCE S256 required) and render the login form
+// @Tags oauth
+// @Produce html
+// @Param client_id query string true "Client ID"
+// @Param redirect_uri query string true "Registered redirect URI"
+// @Param response_type query string true "Must be code"
+// @Param scope query string false "Space-separated scopes, e.g. openid profile email"
+// @Param state query string false "Opaque client state"
+// @Param nonce query string false "ID token nonce"
+// @Param code_challenge query string true "PKCE code challenge"
+// @Param code_challenge_method query string true "Must be S256"
+// @Success 200 {string} string "Login form"
+// @Success 302 "Redirect to the client with an error"
+// @Failure 400 {string} string "Unknown client or redirect URI"
+// @Router /oauth/authorize [get]
+func (h *OAuthHandler) Authorize(c *gin.Context) {
+	var req model.AuthorizeRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.oauthService.ValidateAuthorizeRequest(c.Request.Context(), &req); err != nil {
+		h.authorizeError(c, &req, err)
+		return
+	}
+
+	h.renderLogin(c, http.StatusOK, &req, "", "")
+}
+
+// AuthorizeSubmit godoc
+// @Summary Submit OAuth2 login form
+// @Description Log in (including bans and MFA) and redirect back to the client with an authorization code
+// @Tags oauth
+// @Accept x-www-form-urlencoded
+// @Produce html
+// @Success 302 "Redirect to the client with code and state"
+// @Failure 400 {string} string "Unknown client or redirect URI"
+// @Failure 401 {string} string "Login form with an error"
+// @Failure 423 {string} string "Login form with a ban message"
+// @Router /oauth/authorize [post]
+func (h *OAuthHandler) AuthorizeSubmit(c *gin.Context) {
+	var req model.AuthorizeRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var (
+		result *service.AuthorizeResult
+		err    error
+	)
+	if mfaToken := c.PostForm("mfa_token"); mfaToken != "" {
+		mfa := &model.MFALoginRequest{
+			MFAToken:     mfaToken,
+			Code:         c.PostForm("code"),
+			RecoveryCode: c.PostForm("recovery_code"),
+		}
+		result, err = h.oauthService.AuthorizeMFA(c.Request.Context(), &req, mfa, c.ClientIP(), c.GetHeader("User-Agent"))
+		if err != nil && errors.Is(err, service.ErrInvalidMFACode) {
+			// Challenge hâlâ geçerli, kod tekrar sorulur
+			h.renderLogin(c, http.StatusUnauthorized, &req, mfaToken, err.Error())
+			return
+		}
+	} else {
+		login := &model.LoginRequest{
+			Username: c.PostForm("username"),
+			Password: c.PostForm("password"),
+		}
+		result, err = h.oauthService.Authorize(c.Request.Context(), &req, login, c.ClientIP(), c.GetHeader("User-Agent"))
+	}
+
+	if err != nil {
+		var oauthErr *service.OAuthError
+		if errors.As(err, &oauthErr) || errors.Is(err, service.ErrInvalidOAuthClient) || errors.Is(err, service.ErrInvalidRedirectURI) {
+			h.authorizeError(c, &req, err)
+			return
+		}
+
+		var banErr *service.BanError
+		switch {
+		case errors.As(err, &banErr):
+			c.Header("Retry-After", strconv.FormatInt(banErr.RetryAfter(time.Now()), 10))
+			h.renderLogin(c, http.StatusLocked, &req, "", err.Error())
+		case errors.Is(err, service.ErrInvalidCredentials), errors.Is(err, service.ErrAccountDeactivated),
+			errors.Is(err, service.ErrEmailNotVerified), errors.Is(err, service.ErrInvalidMFAToken):
+			h.renderLogin(c, http.StatusUnauthorized, &req, "", err.Error())
+		default:
+			logger.Logger.Error("OAuth authorize failed - unexpected error",
+				logger.RequestID(c.GetString("request_id")),
+				logger.String("client_id", req.ClientID),
+				logger.Error(err),
+			)
+			h.renderLogin(c, http.StatusInternalServerError, &req, "", "Login failed")
+		}
+		return
+	}
+
+	if result.MFAToken != "" {
+		h.renderLogin(c, http.StatusOK, &req, result.MFAToken, "")
+		return
+	}
+
+	logger.Logger.Info("OAuth authorization granted",
+		logger.RequestID(c.GetString("request_id")),
+		logger.String("client_id", req.ClientID),
+		logger.String("scope", req.Scope),
+		logger.String("ip", c.ClientIP()),
+	)
+	c.Redirect(http.StatusFound, result.RedirectURL)
+}
+
+// Token godoc
+// @Summary OAuth2 token endpoint
+// @Description Exchange an authorization code (with PKCE verifier) or client credentials for an access token
+// @Tags oauth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param grant_type formData string true "authorization_code or client_credentials"
+// @Param code formData string false "Authorization code"
+// @Param redirect_uri formData string false "Redirect URI used in the authorize request"
+// @Param code_verifier formData string false "PKCE code verifier"
+// @Param scope formData string false "Scopes for client_credentials"
+// @Success 200 {object} model.TokenResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /oauth/token [post]
+func (h *OAuthHandler) Token(c *gin.Context) {
+	req := &service.TokenRequest{
+		GrantType:    c.PostForm("grant_type"),
+		Code:         c.PostForm("code"),
+		RedirectURI:  c.PostForm("redirect_uri"),
+		CodeVerifier: c.PostForm("code_verifier"),
+		Scope:        c.PostForm("scope"),
+		Client:       clientCredentials(c),
+	}
+
+	response, err := h.oauthService.Token(c.Request.Context(), req)
+	noStore(c)
+	if err != nil {
+		h.tokenError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// Introspect godoc
+// @Summary OAuth2 token introspection (RFC 7662)
+// @Tags oauth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param token formData string true "Access token"
+// @Success 200 {object} model.IntrospectionResponse
+// @Failure 401 {object} map[string]interface{}
+// @Router /oauth/introspect [post]
+func (h *OAuthHandler) Introspect(c *gin.Context) {
+	response, err := h.oauthService.Introspect(c.Request.Context(), clientCredentials(c), c.PostForm("token"))
+	noStore(c)
+	if err != nil {
+		h.tokenError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// Revoke godoc
+// @Summary OAuth2 token revocation (RFC 7009)
+// @Tags oauth
+// @Accept x-www-form-urlencoded
+// @Param token formData string true "Access token"
+// @Success 200
+// @Failure 401 {object} map[string]interface{}
+// @Router /oauth/revoke [post]
+func (h *OAuthHandler) Revoke(c *gin.Context) {
+	if err := h.oauthService.Revoke(c.Request.Context(), clientCredentials(c), c.PostForm("token")); err != nil {
+		h.tokenError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// UserInfo godoc
+// @Summary OpenID Connect userinfo endpoint
+// @Tags oauth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /oauth/userinfo [get]
+func (h *OAuthHandler) UserInfo(c *gin.Context) {
+	token := strings.TrimSpace(strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer "))
+	claims, err := h.oauthService.UserInfo(c.Request.Context(), token)
+	if err != nil {
+		c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid_token"})
+		return
+	}
+	c.JSON(http.StatusOK, claims)
+}
+
+// Discovery godoc
+// @Summary OpenID Connect discovery document
+// @Tags oauth
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /.well-known/openid-configuration [get]
+func (h *OAuthHandler) Discovery(c *gin.Context) {
+	c.JSON(http.StatusOK, h.oauthService.Discovery())
+}
+
+// JWKS godoc
+// @Summary JSON Web Key Set used to sign ID tokens
+// @Tags oauth
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /oauth/jwks.json [get]
+func (h *OAuthHandler) JWKS(c *gin.Context) {
+	c.JSON(http.StatusOK, h.oauthService.JWKS())
+}
+
+// ListClients godoc
+// @Summary List OAuth clients
+// @Description List registered OAuth/OIDC clients (admin only)
+// @Tags admin
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /admin/oauth/clients [get]
+func (h *OAuthHandler) ListClients(c *gin.Context) {
+	clients, err := h.oauthService.ListClients(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"clients": clients})
+}
+
+// RegisterClient godoc
+// @Summary Register OAuth client
+// @Description Register an OAuth/OIDC client. The client secret is only returned once (admin only)
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param client body model.CreateOAuthClientRequest true "Client"
+// @Success 201 {object} model.OAuthClientResponse
+// @Failure 400 {object} map[string]interface{}
+// @Router /admin/oauth/clients [post]
+func (h *OAuthHandler) RegisterClient(c *gin.Context) {
+	var req model.CreateOAuthClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalidRequest(c, err)
+		return
+	}
+
+	client, err := h.oauthService.RegisterClient(c.Request.Context(), c.GetUint(middleware.ContextUserID), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, client)
+}
+
+// RevokeClient godoc
+// @Summary Revoke OAuth client
+// @Description Revoke an OAuth client and all access tokens issued to it (admin only)
+// @Tags admin
+// @Param id path int true "Client record ID"
+// @Success 204
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/oauth/clients/{id} [delete]
+func (h *OAuthHandler) RevokeClient(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperror.Validation("Invalid client ID"))
+		return
+	}
+
+	if err := h.oauthService.RevokeClient(c.Request.Context(), c.GetUint(middleware.ContextUserID), uint(id)); err != nil {
+		c.Error(err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *OAuthHandler) renderLogin(c *gin.Context, status int, req *model.AuthorizeRequest, mfaToken, message string) {
+	data := loginPageData{
+		Params: map[string]string{
+			"response_type":         req.ResponseType,
+			"client_id":             req.ClientID,
+			"redirect_uri":          req.RedirectURI,
+			"scope":                 req.Scope,
+			"state":                 req.State,
+			"nonce":                 req.Nonce,
+			"code_challenge":        req.CodeChallenge,
+			"code_challenge_method": req.CodeChallengeMethod,
+		},
+		MFAToken: mfaToken,
+		Error:    message,
+	}
+
+	// Login formu başka sitelerde iframe içine alınamasın
+	c.Header("X-Frame-Options", "DENY")
+	c.Header("Content-Security-Policy", "frame-ancestors 'none'")
+	noStore(c)
+	c.Status(status)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	if err := loginPage.Execute(c.Writer, data); err != nil {
+		logger.Logger.Error("Failed to render login page", logger.Error(err))
+	}
+}
+
+// authorizeError client ve redirect_uri doğrulanmışsa hatayı client'a redirect eder, aksi halde gösterir
+func (h *OAuthHandler) authorizeError(c *gin.Context, req *model.AuthorizeRequest, err error) {
+	var oauthErr *service.OAuthError
+	if !errors.As(err, &oauthErr) {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	redirect, parseErr := url.Parse(req.RedirectURI)
+	if parseErr != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	query := redirect.Query()
+	query.Set("error", oauthErr.Code)
+	query.Set("error_description", oauthErr.Description)
+	if req.State != "" {
+		query.Set("state", req.State)
+	}
+	redirect.RawQuery = query.Encode()
+	c.Redirect(http.StatusFound, redirect.String())
+}
+
+// tokenError RFC 6749 5.2 formatında hata döner
+func (h *OAuthHandler) tokenError(c *gin.Context, err error) {
+	var oauthErr *service.OAuthError
+	if !errors.As(err, &oauthErr) {
+		logger.Logger.Error("OAuth token endpoint failed - unexpected error",
+			logger.RequestID(c.GetString("request_id")),
+			logger.Error(err),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server_error"})
+		return
+	}
+
+	status := http.StatusBadRequest
+	if oauthErr.Code == "invalid_client" {
+		status = http.StatusUnauthorized
+		c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+		logger.Logger.Warn("OAuth client authentication failed",
+			logger.SecurityEvent("oauth_client_auth_failed"),
+			logger.RequestID(c.GetString("request_id")),
+			logger.String("ip", c.ClientIP()),
+		)
+	}
+	c.JSON(status, gin.H{"error": oauthErr.Code, "error_description": oauthErr.Description})
+}
+
+// clientCredentials client_secret_basic veya client_secret_post ile gönderilen bilgileri okur
+func clientCredentials(c *gin.Context) service.ClientCredentials {
+	if id, secret, ok := c.Request.BasicAuth(); ok {
+		// RFC 6749 2.3.1: Basic auth değerleri form-urlencoded'dır
+		if unescaped, err := url.QueryUnescape(id); err == nil {
+			id = unescaped
+		}
+		if unescaped, err := url.QueryUnescape(secret); err == nil {
+			secret = unescaped
+		}
+		return service.ClientCredentials{ClientID: id, ClientSecret: secret}
+	}
+	return service.ClientCredentials{
+		ClientID:     c.PostForm("client_id"),
+		ClientSecret: c.PostForm("client_secret"),
+	}
+}
+
+func noStore(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+}
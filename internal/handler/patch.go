@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"elk-stack-user/internal/apperror"
+	"elk-stack-user/internal/jsonpatch"
+	"elk-stack-user/internal/model"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// acceptPatch 415 cevaplarında desteklenen patch formatlarını bildirir (RFC 5789)
+const acceptPatch = jsonpatch.MergePatchType + ", " + jsonpatch.JSONPatchType
+
+// patchFunc Content-Type'a göre seçilen patch uygulayıcısı
+type patchFunc func(doc, patch []byte) ([]byte, error)
+
+// patchFuncFor PATCH isteğinin Content-Type'ına karşılık gelen uygulayıcıyı döner
+func patchFuncFor(contentType string) (patchFunc, bool) {
+	switch contentType {
+	case jsonpatch.MergePatchType:
+		return jsonpatch.MergePatch, true
+	case jsonpatch.JSONPatchType:
+		return jsonpatch.Apply, true
+	}
+	return nil, false
+}
+
+// applyUserPatch patch'i kullanıcının düzenlenebilir dokümanına uygular ve sonucu
+// PUT gövdesiyle aynı binding kurallarıyla doğrular. Dokümanda olmayan alanlar
+// (id, role gibi) eklenemez.
+func applyUserPatch(doc *model.ReplaceUserRequest, patch []byte, apply patchFunc) error {
+	current, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	patched, err := apply(current, patch)
+	if err != nil {
+		return patchError(err)
+	}
+
+	*doc = model.ReplaceUserRequest{}
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(doc); err != nil {
+		if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			return apperror.InvalidFields("request validation failed", []apperror.FieldError{{
+				Field:   strings.Trim(field, `"`),
+				Rule:    "unknown",
+				Message: "is not an editable field",
+			}}, err)
+		}
+		return bindError(err)
+	}
+	if err := binding.Validator.ValidateStruct(doc); err != nil {
+		return bindError(err)
+	}
+	return nil
+}
+
+// patchError bozuk patch'leri 400'e, mevcut dokümana uygulanamayanları 409'a çevirir
+func patchError(err error) error {
+	switch {
+	case errors.Is(err, jsonpatch.ErrInvalidPatch):
+		return apperror.Wrap(apperror.ErrValidation, err.Error(), err)
+	case errors.Is(err, jsonpatch.ErrPathNotFound), errors.Is(err, jsonpatch.ErrTestFailed):
+		return apperror.Wrap(apperror.ErrConflict, err.Error(), err)
+	}
+	return err
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"elk-stack-user/internal/apperror"
+	"elk-stack-user/internal/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// ListDeletedUsers godoc
+// @Summary List deleted users
+// @Description List soft-deleted users, most recently deleted first (admin only)
+// @Tags admin
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /admin/users/deleted [get]
+func (h *UserHandler) ListDeletedUsers(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil {
+		page = 1
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if err != nil {
+		pageSize = 20
+	}
+
+	users, total, err := h.userService.ListDeletedUsers(c.Request.Context(), page, pageSize)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"users": users,
+		"pagination": gin.H{
+			"page":      page,
+			"page_size": pageSize,
+			"total":     total,
+		},
+	})
+}
+
+// RestoreUser godoc
+// @Summary Restore deleted user
+// @Description Undo a soft delete (admin only). Fails with 409 if the username or email has been taken since
+// @Tags admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} model.UserResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /admin/users/{id}/restore [post]
+func (h *UserHandler) RestoreUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperror.Validation("Invalid user ID"))
+		return
+	}
+
+	user, err := h.userService.RestoreUser(c.Request.Context(), c.GetUint(middleware.ContextUserID), uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// PurgeUser godoc
+// @Summary Purge deleted user
+// @Description Permanently remove a soft-deleted user with its sessions, tokens and recovery codes (admin only)
+// @Tags admin
+// @Param id path int true "User ID"
+// @Success 204
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/users/{id} [delete]
+func (h *UserHandler) PurgeUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(apperror.Validation("Invalid user ID"))
+		return
+	}
+
+	if err := h.userService.PurgeUser(c.Request.Context(), c.GetUint(middleware.ContextUserID), uint(id)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"elk-stack-user/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+// parseUserFilter GET /users query parametrelerini model.UserFilter'a çevirir
+func parseUserFilter(c *gin.Context) (*model.UserFilter, error) {
+	filter := &model.UserFilter{
+		UsernamePrefix: strings.TrimSpace(c.Query("username_prefix")),
+		EmailPrefix:    strings.TrimSpace(c.Query("email_prefix")),
+		Query:          strings.TrimSpace(c.Query("q")),
+	}
+
+	if value := c.Query("is_active"); value != "" {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("is_active must be true or false")
+		}
+		filter.IsActive = &b
+	}
+
+	var err error
+	if filter.MinAge, err = queryInt(c, "age_min"); err != nil {
+		return nil, err
+	}
+	if filter.MaxAge, err = queryInt(c, "age_max"); err != nil {
+		return nil, err
+	}
+	if filter.MinAge != nil && filter.MaxAge != nil && *filter.MinAge > *filter.MaxAge {
+		return nil, fmt.Errorf("age_min must not be greater than age_max")
+	}
+
+	if filter.CreatedAfter, err = queryTime(c, "created_after"); err != nil {
+		return nil, err
+	}
+	if filter.CreatedBefore, err = queryTime(c, "created_before"); err != nil {
+		return nil, err
+	}
+
+	if filter.Sort, err = model.ParseUserSort(c.Query("sort")); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+func queryInt(c *gin.Context, key string) (*int, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an integer", key)
+	}
+	return &i, nil
+}
+
+// queryTime RFC 3339 zaman damgası veya YYYY-MM-DD tarih kabul eder
+func queryTime(c *gin.Context, key string) (*time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an RFC 3339 timestamp or YYYY-MM-DD date", key)
+	}
+	return &t, nil
+}
+
+// parseUserListRequest page_size, cursor ve total parametrelerini okur.
+// page_size üst sınırı service tarafında uygulanır. Offset pagination kaldırıldığı için
+// page parametresi sessizce yok sayılmaz, eski client'lar her seferinde ilk sayfayı almasın.
+func parseUserListRequest(c *gin.Context) (*model.UserListRequest, error) {
+	if _, ok := c.GetQuery("page"); ok {
+		return nil, fmt.Errorf("page is no longer supported, use cursor from pagination.next_cursor instead")
+	}
+	req := &model.UserListRequest{
+		PageSize: 10,
+		Cursor:   c.Query("cursor"),
+		Total:    c.Query("total"),
+	}
+	if value := c.Query("page_size"); value != "" {
+		pageSize, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("page_size must be an integer")
+		}
+		req.PageSize = pageSize
+	}
+	switch req.Total {
+	case model.TotalNone, model.TotalExact, model.TotalEstimate:
+	default:
+		return nil, fmt.Errorf("total must be exact or estimate")
+	}
+	return req, nil
+}
+
+// pageLink aynı sorguyu verilen cursor ile tekrarlayan link, cursor boşsa boş döner
+func pageLink(c *gin.Context, cursor string) string {
+	if cursor == "" {
+		return ""
+	}
+	query := c.Request.URL.Query()
+	query.Set("cursor", cursor)
+	link := url.URL{Path: c.Request.URL.Path, RawQuery: query.Encode()}
+	return link.String()
+}
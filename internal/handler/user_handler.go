@@ -1,13 +1,19 @@
 package handler
 
 import (
+	"errors"
+	"html/template"
 	"net/http"
 	"strconv"
 	"time"
+	"elk-stack-user/internal/apperror"
+	"elk-stack-user/internal/jsonpatch"
+	"elk-stack-user/internal/middleware"
 	"elk-stack-user/internal/model"
 	"elk-stack-user/internal/service"
 	"elk-stack-user/internal/logger"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 )
 
 type UserHandler struct {
@@ -41,7 +47,7 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 			logger.String("path", c.Request.URL.Path),
 			logger.String("method", c.Request.Method),
 		)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		invalidRequest(c, err)
 		return
 	}
 
@@ -55,7 +61,15 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 	user, err := h.userService.CreateUser(c.Request.Context(), &req)
 	if err != nil {
 		latency := time.Since(start)
-		if err.Error() == "email already exists" || err.Error() == "username already exists" {
+		switch {
+		case errors.Is(err, service.ErrWeakPassword):
+			logger.Logger.Warn("User creation failed - weak password",
+				logger.RequestID(requestID),
+				logger.String("username", req.Username),
+				logger.String("error", err.Error()),
+				logger.ResponseTime(latency),
+			)
+		case errors.Is(err, apperror.ErrConflict):
 			logger.Logger.Warn("User creation failed - duplicate data",
 				logger.RequestID(requestID),
 				logger.String("username", req.Username),
@@ -63,18 +77,16 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 				logger.String("error", err.Error()),
 				logger.ResponseTime(latency),
 			)
-			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
-			return
+		default:
+			logger.Logger.Error("User creation failed",
+				logger.RequestID(requestID),
+				logger.String("username", req.Username),
+				logger.String("email", req.Email),
+				logger.Error(err),
+				logger.ResponseTime(latency),
+			)
 		}
-		
-		logger.Logger.Error("User creation failed",
-			logger.RequestID(requestID),
-			logger.String("username", req.Username),
-			logger.String("email", req.Email),
-			logger.Error(err),
-			logger.ResponseTime(latency),
-		)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+		c.Error(err)
 		return
 	}
 
@@ -88,33 +100,41 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 		logger.StatusCode(http.StatusCreated),
 	)
 
-	c.JSON(http.StatusCreated, user)
+	writeUser(c, http.StatusCreated, user)
 }
 
 // GetUserByID godoc
 // @Summary Get user by ID
-// @Description Get user information by user ID
+// @Description Get user information by user ID. The response carries an ETag; send it back in If-None-Match to get 304 when unchanged, or in If-Match to make PUT/PATCH/DELETE conditional.
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param id path int true "User ID"
+// @Param If-None-Match header string false "ETag from a previous response"
 // @Success 200 {object} model.UserResponse
+// @Success 304 "Not Modified"
+// @Header 200 {string} ETag "Current version of the user"
 // @Failure 404 {object} map[string]interface{}
 // @Router /users/{id} [get]
 func (h *UserHandler) GetUserByID(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		c.Error(apperror.Validation("Invalid user ID"))
 		return
 	}
 
 	user, err := h.userService.GetUserByID(c.Request.Context(), uint(id))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		c.Error(err)
 		return
 	}
 
+	etag := userETag(user.Version)
+	c.Header("ETag", etag)
+	if notModified(c, etag) {
+		return
+	}
 	c.JSON(http.StatusOK, user)
 }
 
@@ -131,13 +151,13 @@ func (h *UserHandler) GetUserByID(c *gin.Context) {
 func (h *UserHandler) GetUserByEmail(c *gin.Context) {
 	email := c.Query("email")
 	if email == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Email parameter is required"})
+		c.Error(apperror.Validation("Email parameter is required"))
 		return
 	}
 
 	user, err := h.userService.GetUserByEmail(c.Request.Context(), email)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		c.Error(err)
 		return
 	}
 
@@ -146,87 +166,176 @@ func (h *UserHandler) GetUserByEmail(c *gin.Context) {
 
 // GetAllUsers godoc
 // @Summary Get all users
-// @Description Get paginated list of all users
+// @Description Get a cursor-paginated list of users with optional filters and sorting
 // @Tags users
 // @Accept json
 // @Produce json
-// @Param page query int false "Page number (default: 1)"
-// @Param page_size query int false "Page size (default: 10)"
+// @Param page_size query int false "Page size (default: 10, max: MAX_PAGE_SIZE)"
+// @Param cursor query string false "Opaque cursor from a previous response's next_cursor or prev_cursor"
+// @Param total query string false "Include a total count: exact or estimate"
+// @Param is_active query bool false "Filter by active status"
+// @Param age_min query int false "Minimum age (inclusive)"
+// @Param age_max query int false "Maximum age (inclusive)"
+// @Param created_after query string false "Created at or after (RFC 3339 or YYYY-MM-DD)"
+// @Param created_before query string false "Created before (RFC 3339 or YYYY-MM-DD)"
+// @Param username_prefix query string false "Username prefix"
+// @Param email_prefix query string false "Email prefix"
+// @Param q query string false "Free-text search over first and last name"
+// @Param sort query string false "Comma-separated sort fields, '-' for descending (e.g. -created_at,username)"
 // @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
 // @Router /users [get]
 func (h *UserHandler) GetAllUsers(c *gin.Context) {
-	pageStr := c.DefaultQuery("page", "1")
-	pageSizeStr := c.DefaultQuery("page_size", "10")
-
-	page, err := strconv.Atoi(pageStr)
+	filter, err := parseUserFilter(c)
 	if err != nil {
-		page = 1
+		invalidRequest(c, err)
+		return
 	}
-
-	pageSize, err := strconv.Atoi(pageSizeStr)
+	req, err := parseUserListRequest(c)
 	if err != nil {
-		pageSize = 10
+		invalidRequest(c, err)
+		return
 	}
 
-	users, total, err := h.userService.GetAllUsers(c.Request.Context(), page, pageSize)
+	page, err := h.userService.ListUsers(c.Request.Context(), filter, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+		c.Error(err)
 		return
 	}
 
+	pagination := gin.H{
+		"page_size":   req.PageSize,
+		"next_cursor": page.NextCursor,
+		"prev_cursor": page.PrevCursor,
+	}
+	if page.Total != nil {
+		pagination["total"] = *page.Total
+		pagination["total_estimated"] = page.TotalEstimated
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"users": users,
-		"pagination": gin.H{
-			"page":       page,
-			"page_size":  pageSize,
-			"total":      total,
-			"total_pages": (total + int64(pageSize) - 1) / int64(pageSize),
+		"users":      page.Users,
+		"pagination": pagination,
+		"links": gin.H{
+			"next": pageLink(c, page.NextCursor),
+			"prev": pageLink(c, page.PrevCursor),
 		},
 	})
 }
 
-// UpdateUser godoc
-// @Summary Update user
-// @Description Update user information by ID
+// SearchUsers godoc
+// @Summary Search users
+// @Description Full-text (prefix) and fuzzy search over username, first/last name and email local part, best matches first
+// @Tags users
+// @Produce json
+// @Param q query string true "Search text (at least 2 characters)"
+// @Param limit query int false "Maximum results (default: 20, max: MAX_PAGE_SIZE)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /users/search [get]
+func (h *UserHandler) SearchUsers(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil {
+		c.Error(apperror.Validation("limit must be an integer"))
+		return
+	}
+
+	hits, err := h.userService.SearchUsers(c.Request.Context(), c.Query("q"), limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"results": hits})
+}
+
+// ReplaceUser godoc
+// @Summary Replace user
+// @Description Replace the editable fields of a user. This is a full replacement: username, email and is_active are required and omitted first_name, last_name and age are cleared. Use PATCH for partial updates.
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param id path int true "User ID"
-// @Param user body model.UpdateUserRequest true "User update information"
+// @Param user body model.ReplaceUserRequest true "Complete editable user document"
+// @Param If-Match header string false "ETag the update is based on; 412 if the user changed since"
 // @Success 200 {object} model.UserResponse
+// @Header 200 {string} ETag "New version of the user"
 // @Failure 400 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
 // @Failure 409 {object} map[string]interface{}
+// @Failure 412 {object} map[string]interface{}
 // @Router /users/{id} [put]
-func (h *UserHandler) UpdateUser(c *gin.Context) {
+func (h *UserHandler) ReplaceUser(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		c.Error(apperror.Validation("Invalid user ID"))
 		return
 	}
 
-	var req model.UpdateUserRequest
+	var req model.ReplaceUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		invalidRequest(c, err)
 		return
 	}
 
-	user, err := h.userService.UpdateUser(c.Request.Context(), uint(id), &req)
+	user, err := h.userService.ReplaceUser(c.Request.Context(), uint(id), &req, parseIfMatch(c))
 	if err != nil {
-		if err.Error() == "User not found" {
-			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
-			return
-		}
-		if err.Error() == "email already exists" || err.Error() == "username already exists" {
-			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, user)
+	writeUser(c, http.StatusOK, user)
+}
+
+// PatchUser godoc
+// @Summary Patch user
+// @Description Partially update a user with a JSON Merge Patch (RFC 7396) or a JSON Patch (RFC 6902). The patch is applied to the editable document {username, email, first_name, last_name, age, is_active} and the result is validated like a PUT body. In a merge patch null clears a field.
+// @Tags users
+// @Accept application/merge-patch+json
+// @Accept application/json-patch+json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param patch body object true "Merge patch object or array of JSON Patch operations"
+// @Param If-Match header string false "ETag the patch is based on; 412 if the user changed since"
+// @Success 200 {object} model.UserResponse
+// @Header 200 {string} ETag "New version of the user"
+// @Failure 400 {object} map[string]interface{} "Malformed patch or invalid result"
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{} "Duplicate username/email, missing path or failed test operation"
+// @Failure 412 {object} map[string]interface{}
+// @Failure 415 {object} map[string]interface{} "See the Accept-Patch header"
+// @Router /users/{id} [patch]
+func (h *UserHandler) PatchUser(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.Error(apperror.Validation("Invalid user ID"))
+		return
+	}
+
+	apply, ok := patchFuncFor(c.ContentType())
+	if !ok {
+		c.Header("Accept-Patch", acceptPatch)
+		c.Error(apperror.UnsupportedMediaType("Content-Type must be " + jsonpatch.MergePatchType + " or " + jsonpatch.JSONPatchType))
+		return
+	}
+
+	patch, err := c.GetRawData()
+	if err != nil {
+		invalidRequest(c, err)
+		return
+	}
+
+	user, err := h.userService.PatchUser(c.Request.Context(), uint(id), func(doc *model.ReplaceUserRequest) error {
+		return applyUserPatch(doc, patch, apply)
+	}, parseIfMatch(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	writeUser(c, http.StatusOK, user)
 }
 
 // DeleteUser godoc
@@ -236,21 +345,23 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "User ID"
+// @Param If-Match header string false "ETag the delete is based on; 412 if the user changed since"
 // @Success 204 "No Content"
 // @Failure 400 {object} map[string]interface{}
 // @Failure 404 {object} map[string]interface{}
+// @Failure 412 {object} map[string]interface{}
 // @Router /users/{id} [delete]
 func (h *UserHandler) DeleteUser(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		c.Error(apperror.Validation("Invalid user ID"))
 		return
 	}
 
-	err = h.userService.DeleteUser(c.Request.Context(), uint(id))
+	err = h.userService.DeleteUser(c.Request.Context(), uint(id), parseIfMatch(c))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		c.Error(err)
 		return
 	}
 
@@ -259,7 +370,7 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 
 // Login godoc
 // @Summary User login
-// @Description Authenticate user with username/email and password
+// @Description Authenticate user with username/email and password. If two-factor authentication is enabled, an MFA token is returned instead of a session token.
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -267,7 +378,8 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 // @Success 200 {object} model.LoginResponse
 // @Failure 400 {object} map[string]interface{}
 // @Failure 401 {object} map[string]interface{}
-// @Failure 423 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 423 {object} map[string]interface{} "Banned; see Retry-After header and expires_at"
 // @Router /login [post]
 func (h *UserHandler) Login(c *gin.Context) {
 	start := time.Now()
@@ -281,7 +393,7 @@ func (h *UserHandler) Login(c *gin.Context) {
 			logger.String("path", c.Request.URL.Path),
 			logger.String("method", c.Request.Method),
 		)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		invalidRequest(c, err)
 		return
 	}
 
@@ -296,8 +408,8 @@ func (h *UserHandler) Login(c *gin.Context) {
 	if err != nil {
 		latency := time.Since(start)
 		
-		switch err.Error() {
-		case "invalid credentials":
+		switch {
+		case errors.Is(err, service.ErrInvalidCredentials):
 			logger.Logger.Warn("Login failed - invalid credentials",
 				logger.RequestID(requestID),
 				logger.String("username", req.Username),
@@ -305,8 +417,7 @@ func (h *UserHandler) Login(c *gin.Context) {
 				logger.String("error", err.Error()),
 				logger.ResponseTime(latency),
 			)
-			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
-		case "account temporarily banned due to multiple failed login attempts":
+		case errors.Is(err, service.ErrAccountBanned):
 			logger.Logger.Warn("Login blocked - account banned",
 				logger.RequestID(requestID),
 				logger.String("username", req.Username),
@@ -314,8 +425,7 @@ func (h *UserHandler) Login(c *gin.Context) {
 				logger.String("error", err.Error()),
 				logger.ResponseTime(latency),
 			)
-			c.JSON(http.StatusLocked, gin.H{"error": err.Error()})
-		case "account is deactivated":
+		case errors.Is(err, service.ErrAccountDeactivated):
 			logger.Logger.Warn("Login failed - account deactivated",
 				logger.RequestID(requestID),
 				logger.String("username", req.Username),
@@ -323,7 +433,14 @@ func (h *UserHandler) Login(c *gin.Context) {
 				logger.String("error", err.Error()),
 				logger.ResponseTime(latency),
 			)
-			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrEmailNotVerified):
+			logger.Logger.Warn("Login failed - email not verified",
+				logger.RequestID(requestID),
+				logger.String("username", req.Username),
+				logger.String("ip", c.ClientIP()),
+				logger.String("error", err.Error()),
+				logger.ResponseTime(latency),
+			)
 		default:
 			logger.Logger.Error("Login failed - unexpected error",
 				logger.RequestID(requestID),
@@ -332,12 +449,23 @@ func (h *UserHandler) Login(c *gin.Context) {
 				logger.Error(err),
 				logger.ResponseTime(latency),
 			)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Login failed"})
 		}
+		c.Error(err)
 		return
 	}
 
 	latency := time.Since(start)
+	if response.MFARequired {
+		logger.Logger.Info("Login requires MFA",
+			logger.RequestID(requestID),
+			logger.String("username", req.Username),
+			logger.String("ip", c.ClientIP()),
+			logger.ResponseTime(latency),
+		)
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
 	logger.Logger.Info("Login successful",
 		logger.RequestID(requestID),
 		logger.UserID(response.User.ID),
@@ -349,3 +477,341 @@ func (h *UserHandler) Login(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response)
 }
+
+// ChangePassword godoc
+// @Summary Change own password
+// @Description Change the authenticated user's password and revoke their other sessions
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param passwords body model.ChangePasswordRequest true "Current and new password"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]interface{} "Validation error or password policy violations"
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 423 {object} map[string]interface{} "Too many wrong current passwords; see Retry-After header and expires_at"
+// @Router /me/password [post]
+func (h *UserHandler) ChangePassword(c *gin.Context) {
+	start := time.Now()
+	requestID := c.GetString("request_id")
+	userID := c.GetUint(middleware.ContextUserID)
+	sessionID := c.GetUint(middleware.ContextSessionID)
+
+	var req model.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalidRequest(c, err)
+		return
+	}
+
+	err := h.userService.ChangePassword(c.Request.Context(), userID, sessionID, &req, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		latency := time.Since(start)
+		switch {
+		case errors.Is(err, service.ErrWeakPassword):
+			logger.Logger.Warn("Password change failed - weak password",
+				logger.RequestID(requestID),
+				logger.UserID(userID),
+				logger.String("error", err.Error()),
+				logger.ResponseTime(latency),
+			)
+		case errors.Is(err, service.ErrAccountBanned):
+			logger.Logger.Warn("Password change blocked - account banned",
+				logger.RequestID(requestID),
+				logger.UserID(userID),
+				logger.String("ip", c.ClientIP()),
+				logger.String("error", err.Error()),
+				logger.ResponseTime(latency),
+			)
+		case errors.Is(err, service.ErrIncorrectPassword):
+			logger.Logger.Warn("Password change failed - incorrect current password",
+				logger.RequestID(requestID),
+				logger.UserID(userID),
+				logger.String("ip", c.ClientIP()),
+				logger.ResponseTime(latency),
+			)
+		default:
+			logger.Logger.Error("Password change failed",
+				logger.RequestID(requestID),
+				logger.UserID(userID),
+				logger.Error(err),
+				logger.ResponseTime(latency),
+			)
+		}
+		c.Error(err)
+		return
+	}
+
+	logger.Logger.Info("Password changed",
+		logger.RequestID(requestID),
+		logger.SecurityEvent("password_changed"),
+		logger.UserID(userID),
+		logger.String("ip", c.ClientIP()),
+		logger.String("user_agent", c.GetHeader("User-Agent")),
+		logger.ResponseTime(time.Since(start)),
+	)
+
+	c.Status(http.StatusNoContent)
+}
+
+// ForgotPassword godoc
+// @Summary Request a password reset
+// @Description Send a single-use password reset link. The response is the same whether or not the email is registered.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body model.ForgotPasswordRequest true "Account email"
+// @Success 202 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 429 {object} map[string]interface{}
+// @Router /password/forgot [post]
+func (h *UserHandler) ForgotPassword(c *gin.Context) {
+	requestID := c.GetString("request_id")
+
+	var req model.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalidRequest(c, err)
+		return
+	}
+
+	if err := h.userService.ForgotPassword(c.Request.Context(), req.Email, c.ClientIP()); err != nil {
+		// Sınır adresin kayıtlı olup olmamasından bağımsızdır, 429 hesap varlığını açığa çıkarmaz
+		if errors.Is(err, service.ErrMailRateLimited) {
+			logger.Logger.Warn("Password reset request rate limited",
+				logger.RequestID(requestID),
+				logger.String("ip", c.ClientIP()),
+			)
+			c.Error(err)
+			return
+		}
+		logger.Logger.Error("Password reset request failed",
+			logger.RequestID(requestID),
+			logger.String("ip", c.ClientIP()),
+			logger.Error(err),
+		)
+		c.Error(err)
+		return
+	}
+
+	logger.Logger.Info("Password reset requested",
+		logger.RequestID(requestID),
+		logger.SecurityEvent("password_reset_requested"),
+		logger.String("ip", c.ClientIP()),
+	)
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "If an account with that email exists, a password reset link has been sent",
+	})
+}
+
+// resetPasswordPage sıfırlama e-postasındaki linkin açtığı form. Form aynı adrese
+// x-www-form-urlencoded olarak post edilir ve sonuç yine bu sayfada gösterilir.
+var resetPasswordPage = template.Must(template.New("reset").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Reset your password</title></head>
+<body>
+<h1>Reset your password</h1>
+{{if .Error}}<p role="alert">{{.Error}}</p>{{end}}
+{{if .Done}}<p>Your password has been reset. You can now sign in with the new password.</p>
+{{else if .Token}}<form method="post" action="/password/reset">
+<input type="hidden" name="token" value="{{.Token}}">
+<label>New password <input type="password" name="new_password" autocomplete="new-password" autofocus></label>
+<button type="submit">Reset password</button>
+</form>
+{{else}}<p>This link is incomplete. Open the link from the password reset email again.</p>
+{{end}}</body>
+</html>`))
+
+type resetPasswordPageData struct {
+	Token string
+	Error string
+	Done  bool
+}
+
+// ResetPasswordForm godoc
+// @Summary Password reset form
+// @Description Render the form the password reset email links to
+// @Tags auth
+// @Produce html
+// @Param token query string true "Reset token"
+// @Success 200 {string} string "Reset form"
+// @Router /password/reset [get]
+func (h *UserHandler) ResetPasswordForm(c *gin.Context) {
+	h.renderResetPassword(c, http.StatusOK, resetPasswordPageData{Token: c.Query("token")})
+}
+
+// ResetPassword godoc
+// @Summary Reset password
+// @Description Set a new password using a reset token. All sessions of the user are revoked.
+// @Description Form posts from the reset page get the page back instead of JSON.
+// @Tags auth
+// @Accept json,x-www-form-urlencoded
+// @Produce json,html
+// @Param request body model.ResetPasswordRequest true "Reset token and new password"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]interface{}
+// @Router /password/reset [post]
+func (h *UserHandler) ResetPassword(c *gin.Context) {
+	requestID := c.GetString("request_id")
+	fromForm := c.ContentType() == binding.MIMEPOSTForm
+
+	var req model.ResetPasswordRequest
+	if fromForm {
+		req.Token, req.NewPassword = c.PostForm("token"), c.PostForm("new_password")
+		if req.NewPassword == "" {
+			h.renderResetPassword(c, http.StatusBadRequest, resetPasswordPageData{Token: req.Token, Error: "Enter a new password."})
+			return
+		}
+	} else if err := c.ShouldBindJSON(&req); err != nil {
+		invalidRequest(c, err)
+		return
+	}
+
+	user, err := h.userService.ResetPassword(c.Request.Context(), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrWeakPassword):
+			logger.Logger.Warn("Password reset failed - weak password",
+				logger.RequestID(requestID),
+				logger.String("error", err.Error()),
+			)
+		case errors.Is(err, service.ErrInvalidResetToken):
+			logger.Logger.Warn("Password reset failed - invalid token",
+				logger.RequestID(requestID),
+				logger.String("ip", c.ClientIP()),
+			)
+		default:
+			logger.Logger.Error("Password reset failed",
+				logger.RequestID(requestID),
+				logger.Error(err),
+			)
+		}
+		if fromForm {
+			// Zayıf şifrede token harcanmaz, form tekrar gösterilir
+			page := resetPasswordPageData{Error: err.Error()}
+			if errors.Is(err, service.ErrWeakPassword) {
+				page.Token = req.Token
+			}
+			h.renderResetPassword(c, apperror.Status(err), page)
+			return
+		}
+		c.Error(err)
+		return
+	}
+
+	logger.Logger.Info("Password reset",
+		logger.RequestID(requestID),
+		logger.SecurityEvent("password_reset"),
+		logger.UserID(user.ID),
+		logger.String("ip", c.ClientIP()),
+	)
+
+	if fromForm {
+		h.renderResetPassword(c, http.StatusOK, resetPasswordPageData{Done: true})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *UserHandler) renderResetPassword(c *gin.Context, status int, data resetPasswordPageData) {
+	// Token içeren sayfa cache'lenmesin, başka sitelerde iframe içine alınamasın
+	c.Header("X-Frame-Options", "DENY")
+	c.Header("Content-Security-Policy", "frame-ancestors 'none'")
+	c.Header("Referrer-Policy", "no-referrer")
+	noStore(c)
+	c.Status(status)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	if err := resetPasswordPage.Execute(c.Writer, data); err != nil {
+		logger.Logger.Error("Failed to render password reset page", logger.Error(err))
+	}
+}
+
+// VerifyEmail godoc
+// @Summary Verify email address
+// @Description Confirm ownership of the account email using the token from the verification email
+// @Tags auth
+// @Produce json
+// @Param token query string true "Verification token"
+// @Success 200 {object} model.UserResponse
+// @Failure 400 {object} map[string]interface{}
+// @Router /verify-email [get]
+func (h *UserHandler) VerifyEmail(c *gin.Context) {
+	requestID := c.GetString("request_id")
+
+	token := c.Query("token")
+	if token == "" {
+		c.Error(apperror.Validation("Token parameter is required"))
+		return
+	}
+
+	user, err := h.userService.VerifyEmail(c.Request.Context(), token)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidVerifyToken) {
+			logger.Logger.Warn("Email verification failed - invalid token",
+				logger.RequestID(requestID),
+				logger.String("ip", c.ClientIP()),
+			)
+		} else {
+			logger.Logger.Error("Email verification failed",
+				logger.RequestID(requestID),
+				logger.Error(err),
+			)
+		}
+		c.Error(err)
+		return
+	}
+
+	logger.Logger.Info("Email verified",
+		logger.RequestID(requestID),
+		logger.SecurityEvent("email_verified"),
+		logger.UserID(user.ID),
+		logger.String("email", user.Email),
+	)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":           "Email address verified",
+		"email_verified_at": user.EmailVerifiedAt,
+	})
+}
+
+// ResendVerificationEmail godoc
+// @Summary Resend verification email
+// @Description Send a new verification email. The response is the same whether or not the email is registered.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body model.ResendVerificationRequest true "Account email"
+// @Success 202 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 429 {object} map[string]interface{}
+// @Router /verify-email/resend [post]
+func (h *UserHandler) ResendVerificationEmail(c *gin.Context) {
+	requestID := c.GetString("request_id")
+
+	var req model.ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalidRequest(c, err)
+		return
+	}
+
+	if err := h.userService.ResendVerificationEmail(c.Request.Context(), req.Email, c.ClientIP()); err != nil {
+		if errors.Is(err, service.ErrMailRateLimited) {
+			logger.Logger.Warn("Verification email resend rate limited",
+				logger.RequestID(requestID),
+				logger.String("ip", c.ClientIP()),
+			)
+		} else {
+			logger.Logger.Error("Verification email resend failed",
+				logger.RequestID(requestID),
+				logger.String("ip", c.ClientIP()),
+				logger.Error(err),
+			)
+		}
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "If an unverified account with that email exists, a verification email has been sent",
+	})
+}
@@ -0,0 +1,227 @@
+// Package ipacl statik ve veritabanı kaynaklı IP allow/deny listelerini uygular
+package ipacl
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"elk-stack-user/internal/logger"
+	"elk-stack-user/internal/model"
+	"elk-stack-user/internal/repository"
+)
+
+type Config struct {
+	// DenyList tüm isteklerde reddedilecek CIDR'lar
+	DenyList []string
+	// AdminAllowList boş değilse /admin route'ları sadece bu CIDR'lardan erişilebilir
+	AdminAllowList []string
+	// TrustedProxies X-Forwarded-For/X-Real-IP header'larına güvenilecek proxy'ler.
+	// Boşsa header'lar yok sayılır ve client IP her zaman TCP bağlantısının adresidir.
+	TrustedProxies []string
+	// RefreshInterval veritabanındaki kuralların ne sıklıkla yeniden yükleneceği
+	RefreshInterval time.Duration
+}
+
+func NewConfig() *Config {
+	return &Config{
+		DenyList:        getEnvList("IP_DENY_LIST"),
+		AdminAllowList:  getEnvList("ADMIN_IP_ALLOW_LIST"),
+		TrustedProxies:  getEnvList("TRUSTED_PROXIES"),
+		RefreshInterval: getEnvDuration("IP_RULES_REFRESH_INTERVAL", 30*time.Second),
+	}
+}
+
+// ACL o anki kural setini atomik olarak değiştirilen bir snapshot'ta tutar,
+// böylece request path'inde lock alınmaz
+type ACL struct {
+	config      *Config
+	repo        repository.IPRuleRepository
+	staticDeny  []netip.Prefix
+	staticAllow []netip.Prefix
+	rules       atomic.Pointer[ruleSet]
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+type ruleSet struct {
+	deny       *Trie
+	adminAllow *Trie
+}
+
+// New statik listeleri parse eder, repo nil ise sadece statik kurallar kullanılır
+func New(config *Config, repo repository.IPRuleRepository) (*ACL, error) {
+	staticDeny, err := parsePrefixes(config.DenyList)
+	if err != nil {
+		return nil, fmt.Errorf("IP_DENY_LIST: %w", err)
+	}
+	staticAllow, err := parsePrefixes(config.AdminAllowList)
+	if err != nil {
+		return nil, fmt.Errorf("ADMIN_IP_ALLOW_LIST: %w", err)
+	}
+	if _, err := parsePrefixes(config.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("TRUSTED_PROXIES: %w", err)
+	}
+
+	acl := &ACL{
+		config:      config,
+		repo:        repo,
+		staticDeny:  staticDeny,
+		staticAllow: staticAllow,
+	}
+	acl.rules.Store(acl.build(nil))
+	return acl, nil
+}
+
+// TrustedProxies gin.Engine.SetTrustedProxies için
+func (a *ACL) TrustedProxies() []string {
+	return a.config.TrustedProxies
+}
+
+// Denied IP deny listesindeyse true döner
+func (a *ACL) Denied(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	return a.rules.Load().deny.Contains(addr)
+}
+
+// AdminAllowed admin allow listesi boşsa veya IP listedeyse true döner
+func (a *ACL) AdminAllowed(ip string) bool {
+	allow := a.rules.Load().adminAllow
+	if allow.Len() == 0 {
+		return true
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	return allow.Contains(addr)
+}
+
+// AdminAllowedWith IP'nin allow listesine prefix eklendikten sonra admin route'larına
+// erişip erişemeyeceğini döner. Liste boşken eklenen ilk allow kuralı kısıtlamayı açtığı
+// için, sadece prefix'in kapsadığı IP'ler erişimini korur.
+func (a *ACL) AdminAllowedWith(ip string, prefix netip.Prefix) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	if prefix.Contains(addr.Unmap()) {
+		return true
+	}
+	allow := a.rules.Load().adminAllow
+	return allow.Len() > 0 && allow.Contains(addr)
+}
+
+// Refresh veritabanındaki aktif kuralları yükleyip snapshot'ı değiştirir
+func (a *ACL) Refresh(ctx context.Context) error {
+	if a.repo == nil {
+		return nil
+	}
+	rules, err := a.repo.ListActive(ctx)
+	if err != nil {
+		return err
+	}
+	a.rules.Store(a.build(rules))
+	return nil
+}
+
+// Start kuralları yükler ve RefreshInterval'da bir yenileyen goroutine başlatır
+func (a *ACL) Start(ctx context.Context) {
+	if err := a.Refresh(ctx); err != nil {
+		logger.Logger.Error("Failed to load IP rules", logger.Error(err))
+	}
+	if a.repo == nil || a.config.RefreshInterval <= 0 {
+		return
+	}
+
+	ctx, a.cancel = context.WithCancel(ctx)
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		ticker := time.NewTicker(a.config.RefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := a.Refresh(ctx); err != nil {
+					logger.Logger.Error("Failed to refresh IP rules", logger.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+func (a *ACL) Stop() {
+	if a.cancel != nil {
+		a.cancel()
+	}
+	a.wg.Wait()
+}
+
+func (a *ACL) build(rules []*model.IPRule) *ruleSet {
+	set := &ruleSet{deny: NewTrie(), adminAllow: NewTrie()}
+	for _, p := range a.staticDeny {
+		set.deny.Insert(p)
+	}
+	for _, p := range a.staticAllow {
+		set.adminAllow.Insert(p)
+	}
+
+	for _, rule := range rules {
+		prefix, err := ParsePrefix(rule.CIDR)
+		if err != nil {
+			logger.Logger.Warn("Skipping invalid IP rule",
+				logger.Uint("rule_id", rule.ID),
+				logger.String("cidr", rule.CIDR),
+			)
+			continue
+		}
+		switch rule.Action {
+		case model.IPRuleDeny:
+			set.deny.Insert(prefix)
+		case model.IPRuleAllow:
+			set.adminAllow.Insert(prefix)
+		}
+	}
+	return set
+}
+
+func parsePrefixes(values []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(values))
+	for _, v := range values {
+		p, err := ParsePrefix(v)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, p)
+	}
+	return prefixes, nil
+}
+
+func getEnvList(key string) []string {
+	var values []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(key)); err == nil {
+		return d
+	}
+	return defaultValue
+}
@@ -0,0 +1,36 @@
+package ipacl
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestAdminAllowedWith(t *testing.T) {
+	tests := []struct {
+		name      string
+		allowList []string
+		ip        string
+		prefix    string
+		want      bool
+	}{
+		{name: "empty list, prefix covers caller", ip: "203.0.113.9", prefix: "203.0.113.0/24", want: true},
+		{name: "empty list, prefix excludes caller", ip: "198.51.100.1", prefix: "203.0.113.0/24", want: false},
+		{name: "empty list, mapped caller", ip: "::ffff:203.0.113.9", prefix: "203.0.113.0/24", want: true},
+		{name: "caller already allowed", allowList: []string{"198.51.100.0/24"}, ip: "198.51.100.1", prefix: "203.0.113.0/24", want: true},
+		{name: "caller not in existing list", allowList: []string{"192.0.2.0/24"}, ip: "198.51.100.1", prefix: "203.0.113.0/24", want: false},
+		{name: "v6 caller", ip: "2001:db8::1", prefix: "2001:db8::/32", want: true},
+		{name: "invalid caller IP", ip: "unknown", prefix: "0.0.0.0/0", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			acl, err := New(&Config{AdminAllowList: tt.allowList}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := acl.AdminAllowedWith(tt.ip, netip.MustParsePrefix(tt.prefix)); got != tt.want {
+				t.Errorf("AdminAllowedWith(%s, %s) = %v, want %v", tt.ip, tt.prefix, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package ipacl
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// Trie CIDR prefix'lerini bit bazlı binary trie'da tutar.
+// Lookup adres uzunluğu ile sınırlı: IPv4 için en fazla 32, IPv6 için 128 adım.
+type Trie struct {
+	v4   *node
+	v6   *node
+	size int
+}
+
+type node struct {
+	children [2]*node
+	terminal bool
+}
+
+func NewTrie() *Trie {
+	return &Trie{v4: &node{}, v6: &node{}}
+}
+
+// Insert prefix'i ekler, IPv4-mapped IPv6 prefix'ler IPv4 olarak saklanır
+func (t *Trie) Insert(prefix netip.Prefix) {
+	addr, bits := prefix.Addr(), prefix.Bits()
+	if addr.Is4In6() && bits >= 96 {
+		addr, bits = addr.Unmap(), bits-96
+	}
+
+	n := t.root(addr)
+	raw := addr.AsSlice()
+	for i := 0; i < bits; i++ {
+		b := bit(raw, i)
+		if n.children[b] == nil {
+			n.children[b] = &node{}
+		}
+		n = n.children[b]
+	}
+	if !n.terminal {
+		n.terminal = true
+		t.size++
+	}
+}
+
+// Contains adres eklenen prefix'lerden herhangi birinin içindeyse true döner
+func (t *Trie) Contains(addr netip.Addr) bool {
+	if !addr.IsValid() {
+		return false
+	}
+	addr = addr.Unmap()
+
+	n := t.root(addr)
+	raw := addr.AsSlice()
+	for i := 0; n != nil; i++ {
+		if n.terminal {
+			return true
+		}
+		if i == len(raw)*8 {
+			return false
+		}
+		n = n.children[bit(raw, i)]
+	}
+	return false
+}
+
+// Len eklenen farklı prefix sayısı
+func (t *Trie) Len() int {
+	return t.size
+}
+
+func (t *Trie) root(addr netip.Addr) *node {
+	if addr.Is4() {
+		return t.v4
+	}
+	return t.v6
+}
+
+func bit(raw []byte, i int) int {
+	return int(raw[i/8]>>(7-uint(i%8))) & 1
+}
+
+// ParsePrefix CIDR veya tek bir IP adresi kabul eder (tek IP /32 veya /128 olur).
+// IPv4-mapped prefix'ler (::ffff:a.b.c.d/n) IPv4 prefix'e çevrilir; Contains adresleri
+// unmap ederek aradığı için /96'dan kısa mapped prefix'ler hiç eşleşmez ve reddedilir.
+func ParsePrefix(s string) (netip.Prefix, error) {
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, "/") {
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid CIDR %q", s)
+		}
+		if addr, bits := prefix.Addr(), prefix.Bits(); addr.Is4In6() {
+			if bits < 96 {
+				return netip.Prefix{}, fmt.Errorf("invalid CIDR %q: IPv4-mapped prefixes must be /96 or longer", s)
+			}
+			prefix = netip.PrefixFrom(addr.Unmap(), bits-96)
+		}
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid IP address %q", s)
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
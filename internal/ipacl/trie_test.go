@@ -0,0 +1,135 @@
+package ipacl
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestParsePrefix(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "10.0.0.0/8", want: "10.0.0.0/8"},
+		{in: " 192.168.1.77/24 ", want: "192.168.1.0/24"},
+		{in: "203.0.113.9", want: "203.0.113.9/32"},
+		{in: "2001:db8::/32", want: "2001:db8::/32"},
+		{in: "2001:db8::1:2/64", want: "2001:db8::/64"},
+		{in: "2001:db8::1", want: "2001:db8::1/128"},
+		{in: "::ffff:203.0.113.9", want: "203.0.113.9/32"},
+		{in: "::ffff:10.1.2.3/104", want: "10.0.0.0/8"},
+		{in: "::ffff:0:0/96", want: "0.0.0.0/0"},
+		{in: "::ffff:10.0.0.0/95", wantErr: true},
+		{in: "::ffff:10.0.0.0/64", wantErr: true},
+		{in: "10.0.0.0/33", wantErr: true},
+		{in: "2001:db8::/129", wantErr: true},
+		{in: "10.0.0.256", wantErr: true},
+		{in: "not-an-ip", wantErr: true},
+		{in: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParsePrefix(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParsePrefix(%q) = %s, want error", tt.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParsePrefix(%q): %v", tt.in, err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("ParsePrefix(%q) = %s, want %s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrieContains(t *testing.T) {
+	trie := NewTrie()
+	for _, p := range []string{"10.0.0.0/8", "192.168.1.0/24", "203.0.113.9", "2001:db8::/32", "2001:db8:ffff::1", "::ffff:198.51.100.0/120"} {
+		prefix, err := ParsePrefix(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		trie.Insert(prefix)
+	}
+
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"10.0.0.1", true},
+		{"10.255.255.255", true},
+		{"11.0.0.1", false},
+		{"192.168.1.200", true},
+		{"192.168.2.1", false},
+		{"203.0.113.9", true},
+		{"203.0.113.10", false},
+		{"198.51.100.7", true},
+		{"198.51.101.7", false},
+		// IPv4-mapped adresler IPv4 kurallarıyla eşleşir
+		{"::ffff:10.1.2.3", true},
+		{"::ffff:198.51.100.7", true},
+		{"::ffff:11.0.0.1", false},
+		{"2001:db8::1", true},
+		{"2001:db8:1234::abcd", true},
+		{"2001:db9::1", false},
+		// IPv4 ve IPv6 ağaçları ayrıdır: ilk byte'ı 0x0a olan v6 adres 10.0.0.0/8'e takılmaz
+		{"a00::1", false},
+	}
+
+	for _, tt := range tests {
+		if got := trie.Contains(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("Contains(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+	if trie.Contains(netip.Addr{}) {
+		t.Error("Contains(invalid) = true, want false")
+	}
+}
+
+func TestTrieInsertMappedPrefix(t *testing.T) {
+	// Insert doğrudan çağrıldığında da /96 ve üzeri mapped prefix'ler IPv4 olarak saklanır
+	trie := NewTrie()
+	trie.Insert(netip.MustParsePrefix("::ffff:10.0.0.0/104"))
+
+	if !trie.Contains(netip.MustParseAddr("10.9.9.9")) {
+		t.Error("mapped /104 does not match IPv4 address")
+	}
+	if !trie.Contains(netip.MustParseAddr("::ffff:10.9.9.9")) {
+		t.Error("mapped /104 does not match mapped address")
+	}
+}
+
+func TestTrieLen(t *testing.T) {
+	trie := NewTrie()
+	if trie.Len() != 0 {
+		t.Fatalf("empty trie Len = %d", trie.Len())
+	}
+	for _, p := range []string{"10.0.0.0/8", "10.0.0.0/8", "::ffff:10.0.0.0/104", "2001:db8::/32"} {
+		prefix, err := ParsePrefix(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		trie.Insert(prefix)
+	}
+	if trie.Len() != 2 {
+		t.Errorf("Len = %d, want 2 (duplicates and mapped duplicates count once)", trie.Len())
+	}
+}
+
+func TestTrieMatchAll(t *testing.T) {
+	trie := NewTrie()
+	trie.Insert(netip.MustParsePrefix("0.0.0.0/0"))
+
+	if !trie.Contains(netip.MustParseAddr("8.8.8.8")) {
+		t.Error("0.0.0.0/0 does not match IPv4 address")
+	}
+	if trie.Contains(netip.MustParseAddr("2001:db8::1")) {
+		t.Error("0.0.0.0/0 matches IPv6 address")
+	}
+}
@@ -0,0 +1,351 @@
+// Package jsonpatch JSON dokümanlarına JSON Merge Patch (RFC 7396) ve JSON Patch
+// (RFC 6902) uygular. Dokümanlar encoding/json'un genel tiplerine (map, slice,
+// float64, string, bool, nil) açılarak işlenir.
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Content type'lar
+const (
+	MergePatchType = "application/merge-patch+json"
+	JSONPatchType  = "application/json-patch+json"
+)
+
+var (
+	// ErrInvalidPatch patch dokümanı bozuk veya RFC'ye uymuyor
+	ErrInvalidPatch = errors.New("invalid patch document")
+	// ErrPathNotFound bir operasyonun hedeflediği konum dokümanda yok
+	ErrPathNotFound = errors.New("path not found")
+	// ErrTestFailed bir "test" operasyonunun değeri eşleşmedi
+	ErrTestFailed = errors.New("test operation failed")
+)
+
+// MergePatch patch'i RFC 7396'ya göre doc'a uygular: null değerler alanı siler,
+// nesneler özyinelemeli birleştirilir, diğer her değer olduğu gibi yazılır.
+func MergePatch(doc, patch []byte) ([]byte, error) {
+	var target, p interface{}
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, fmt.Errorf("invalid target document: %w", err)
+	}
+	if err := json.Unmarshal(patch, &p); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	return json.Marshal(mergePatch(target, p))
+}
+
+func mergePatch(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = map[string]interface{}{}
+	}
+	for name, value := range patchObj {
+		if value == nil {
+			delete(targetObj, name)
+			continue
+		}
+		targetObj[name] = mergePatch(targetObj[name], value)
+	}
+	return targetObj
+}
+
+// Apply RFC 6902 operasyon listesini sırayla doc'a uygular. Operasyonlardan biri
+// başarısız olursa hata döner ve doc'un hiçbir değişikliği sonuca yansımaz.
+func Apply(doc, patch []byte) ([]byte, error) {
+	var ops []map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, fmt.Errorf("%w: must be a JSON array of operations", ErrInvalidPatch)
+	}
+
+	var target interface{}
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, fmt.Errorf("invalid target document: %w", err)
+	}
+
+	for i, raw := range ops {
+		op, err := parseOperation(raw)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d: %w", i, err)
+		}
+		if target, err = op.apply(target); err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %w", i, op.op, op.path, err)
+		}
+	}
+	return json.Marshal(target)
+}
+
+type operation struct {
+	op   string
+	path string
+	from string
+	// value add/replace/test'in "value" üyesi, null da geçerli bir değerdir
+	value interface{}
+}
+
+func parseOperation(raw map[string]json.RawMessage) (*operation, error) {
+	op := &operation{}
+	if err := decodeMember(raw, "op", &op.op); err != nil {
+		return nil, err
+	}
+	if err := decodeMember(raw, "path", &op.path); err != nil {
+		return nil, err
+	}
+
+	switch op.op {
+	case "add", "replace", "test":
+		value, ok := raw["value"]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q requires a value", ErrInvalidPatch, op.op)
+		}
+		if err := json.Unmarshal(value, &op.value); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+		}
+	case "move", "copy":
+		if err := decodeMember(raw, "from", &op.from); err != nil {
+			return nil, err
+		}
+	case "remove":
+	default:
+		return nil, fmt.Errorf("%w: unknown op %q", ErrInvalidPatch, op.op)
+	}
+	return op, nil
+}
+
+// decodeMember zorunlu bir string üyeyi okur
+func decodeMember(raw map[string]json.RawMessage, name string, dst *string) error {
+	value, ok := raw[name]
+	if !ok {
+		return fmt.Errorf("%w: missing %q", ErrInvalidPatch, name)
+	}
+	if err := json.Unmarshal(value, dst); err != nil {
+		return fmt.Errorf("%w: %q must be a string", ErrInvalidPatch, name)
+	}
+	return nil
+}
+
+func (op *operation) apply(doc interface{}) (interface{}, error) {
+	path, err := parsePointer(op.path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.op {
+	case "add":
+		return add(doc, path, op.value)
+	case "remove":
+		return remove(doc, path)
+	case "replace":
+		if _, err := get(doc, path); err != nil {
+			return nil, err
+		}
+		if len(path) == 0 {
+			return op.value, nil
+		}
+		return modify(doc, path, func(parent interface{}, key string) (interface{}, error) {
+			switch p := parent.(type) {
+			case map[string]interface{}:
+				p[key] = op.value
+				return p, nil
+			case []interface{}:
+				i, err := arrayIndex(key, len(p), false)
+				if err != nil {
+					return nil, err
+				}
+				p[i] = op.value
+				return p, nil
+			}
+			return nil, ErrPathNotFound
+		})
+	case "test":
+		current, err := get(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(current, op.value) {
+			return nil, ErrTestFailed
+		}
+		return doc, nil
+	}
+
+	from, err := parsePointer(op.from)
+	if err != nil {
+		return nil, err
+	}
+	value, err := get(doc, from)
+	if err != nil {
+		return nil, err
+	}
+	if op.op == "copy" {
+		return add(doc, path, deepCopy(value))
+	}
+
+	// move: bir konum kendi altına taşınamaz
+	if len(path) > len(from) && isPrefix(from, path) {
+		return nil, fmt.Errorf("%w: cannot move %q into its own child", ErrInvalidPatch, op.from)
+	}
+	if doc, err = remove(doc, from); err != nil {
+		return nil, err
+	}
+	return add(doc, path, value)
+}
+
+// parsePointer RFC 6901 JSON Pointer'ı token'lara ayırır, "" dokümanın kendisidir
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("%w: pointer %q must start with '/'", ErrInvalidPatch, pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func isPrefix(prefix, path []string) bool {
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// arrayIndex dizi token'ını indekse çevirir; "-" ve len sadece ekleme için geçerlidir
+func arrayIndex(token string, length int, insert bool) (int, error) {
+	if token == "-" && insert {
+		return length, nil
+	}
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("%w: invalid array index %q", ErrPathNotFound, token)
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i > length || (i == length && !insert) {
+		return 0, fmt.Errorf("%w: array index %q out of range", ErrPathNotFound, token)
+	}
+	return i, nil
+}
+
+func get(doc interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, ErrPathNotFound
+			}
+			doc = value
+		case []interface{}:
+			i, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			doc = node[i]
+		default:
+			return nil, ErrPathNotFound
+		}
+	}
+	return doc, nil
+}
+
+// modify path'in son token'ının ebeveynine kadar iner ve leaf'i çağırır. Dizilere
+// eleman eklenip çıkarılabildiği için her seviye güncellenmiş çocuğu geri yazar.
+func modify(node interface{}, path []string, leaf func(parent interface{}, key string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return leaf(node, path[0])
+	}
+
+	child, err := get(node, path[:1])
+	if err != nil {
+		return nil, err
+	}
+	updated, err := modify(child, path[1:], leaf)
+	if err != nil {
+		return nil, err
+	}
+	switch n := node.(type) {
+	case map[string]interface{}:
+		n[path[0]] = updated
+	case []interface{}:
+		i, _ := arrayIndex(path[0], len(n), false)
+		n[i] = updated
+	}
+	return node, nil
+}
+
+func add(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return modify(doc, path, func(parent interface{}, key string) (interface{}, error) {
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			p[key] = value
+			return p, nil
+		case []interface{}:
+			i, err := arrayIndex(key, len(p), true)
+			if err != nil {
+				return nil, err
+			}
+			p = append(p, nil)
+			copy(p[i+1:], p[i:])
+			p[i] = value
+			return p, nil
+		}
+		return nil, ErrPathNotFound
+	})
+}
+
+func remove(doc interface{}, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("%w: cannot remove the whole document", ErrInvalidPatch)
+	}
+	return modify(doc, path, func(parent interface{}, key string) (interface{}, error) {
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			if _, ok := p[key]; !ok {
+				return nil, ErrPathNotFound
+			}
+			delete(p, key)
+			return p, nil
+		case []interface{}:
+			i, err := arrayIndex(key, len(p), false)
+			if err != nil {
+				return nil, err
+			}
+			return append(p[:i], p[i+1:]...), nil
+		}
+		return nil, ErrPathNotFound
+	})
+}
+
+// deepCopy copy operasyonunda iki konumun aynı map/slice'ı paylaşmasını önler
+func deepCopy(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			copied[key] = deepCopy(item)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = deepCopy(item)
+		}
+		return copied
+	}
+	return value
+}
@@ -98,6 +98,10 @@ func Int(key string, val int) zap.Field {
 	return zap.Int(key, val)
 }
 
+func Int64(key string, val int64) zap.Field {
+	return zap.Int64(key, val)
+}
+
 func Uint(key string, val uint) zap.Field {
 	return zap.Uint(key, val)
 }
@@ -184,3 +188,8 @@ func Email(email string) zap.Field {
 func ServiceName(name string) zap.Field {
 	return zap.String("service", name)
 }
+
+// Güvenlik olayları için (Kibana'da security_event ile filtrelenir)
+func SecurityEvent(event string) zap.Field {
+	return zap.String("security_event", event)
+}
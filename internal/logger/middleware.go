@@ -1,8 +1,11 @@
 package logger
 
 import (
+	"crypto/rand"
+	"strconv"
 	"time"
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 // RequestIDMiddleware request ID ekler
@@ -49,7 +52,7 @@ func LoggingMiddleware() gin.HandlerFunc {
 		bodySize := c.Writer.Size()
 
 		// Response log'u
-		Logger.Info("HTTP Request Completed",
+		fields := []zap.Field{
 			RequestID(requestID),
 			Method(method),
 			Path(path),
@@ -57,7 +60,15 @@ func LoggingMiddleware() gin.HandlerFunc {
 			ResponseTime(latency),
 			Int("body_size", bodySize),
 			ClientIP(clientIP),
-		)
+		}
+		// Auth middleware'inin set ettiği kimlik bilgileri
+		if userID, ok := c.Get("user_id"); ok {
+			fields = append(fields, Any("user_id", userID))
+		}
+		if apiKeyID, ok := c.Get("api_key_id"); ok {
+			fields = append(fields, Any("api_key_id", apiKeyID))
+		}
+		Logger.Info("HTTP Request Completed", fields...)
 
 		// Error log'u (4xx, 5xx status kodları için)
 		if statusCode >= 400 {
@@ -83,8 +94,12 @@ func generateRequestID() string {
 func randomString(length int) string {
 	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 	b := make([]byte, length)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand hata verirse en azından zamana dayalı bir değer dön
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
 	for i := range b {
-		b[i] = charset[time.Now().UnixNano()%int64(len(charset))]
+		b[i] = charset[int(b[i])%len(charset)]
 	}
 	return string(b)
 }
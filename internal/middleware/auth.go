@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"strconv"
+	"strings"
+
+	"elk-stack-user/internal/apperror"
+	"elk-stack-user/internal/model"
+	"elk-stack-user/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// Context keys set by RequireAuth and Authenticate
+const (
+	ContextUserID       = "user_id"
+	ContextSessionID    = "session_id"
+	ContextUserRole     = "user_role"
+	ContextAPIKeyID     = "api_key_id"
+	ContextAPIKeyScopes = "api_key_scopes"
+)
+
+// RequireAuth validates the "Authorization: Bearer <token>" header against
+// active sessions and stores the authenticated user in the gin context.
+func RequireAuth(userService service.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := credential(c.GetHeader("Authorization"), "Bearer")
+		if !ok {
+			abortWithProblem(c, apperror.Unauthorized("authorization required"))
+			return
+		}
+		if authenticateUser(c, userService, token) {
+			c.Next()
+		}
+	}
+}
+
+// Authenticate accepts either a user session ("Authorization: Bearer <token>")
+// or a service API key ("Authorization: ApiKey <key>").
+func Authenticate(userService service.UserService, apiKeyService service.APIKeyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		if header == "" {
+			abortWithProblem(c, apperror.Unauthorized("authorization required"))
+			return
+		}
+
+		if token, ok := credential(header, "Bearer"); ok {
+			if authenticateUser(c, userService, token) {
+				c.Next()
+			}
+			return
+		}
+
+		if rawKey, ok := credential(header, "ApiKey"); ok {
+			key, err := apiKeyService.Authenticate(c.Request.Context(), rawKey, c.ClientIP())
+			if err != nil {
+				abortWithProblem(c, err)
+				return
+			}
+			c.Set(ContextAPIKeyID, key.ID)
+			c.Set(ContextAPIKeyScopes, key.ScopeList())
+			c.Next()
+			return
+		}
+
+		abortWithProblem(c, apperror.Unauthorized("unsupported authorization scheme"))
+	}
+}
+
+// RequireAdmin must run after RequireAuth and rejects non-admin users.
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString(ContextUserRole) != model.RoleAdmin {
+			abortWithProblem(c, apperror.Forbidden("admin access required"))
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireScope must run after Authenticate. API keys need the given scope.
+// User sessions need the admin role, or must target their own account on
+// routes with an :id parameter.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, isKey := c.Get(ContextAPIKeyID); isKey {
+			for _, s := range c.GetStringSlice(ContextAPIKeyScopes) {
+				if s == scope {
+					c.Next()
+					return
+				}
+			}
+			abortWithProblem(c, apperror.Forbidden("API key is missing scope "+scope))
+			return
+		}
+
+		if c.GetString(ContextUserRole) == model.RoleAdmin {
+			c.Next()
+			return
+		}
+		if id := c.Param("id"); id != "" && id == strconv.FormatUint(uint64(c.GetUint(ContextUserID)), 10) {
+			c.Next()
+			return
+		}
+		abortWithProblem(c, apperror.Forbidden("admin access required"))
+	}
+}
+
+func authenticateUser(c *gin.Context, userService service.UserService, token string) bool {
+	user, session, err := userService.Authenticate(c.Request.Context(), token)
+	if err != nil {
+		abortWithProblem(c, err)
+		return false
+	}
+
+	c.Set(ContextUserID, user.ID)
+	c.Set(ContextSessionID, session.ID)
+	c.Set(ContextUserRole, user.Role)
+	return true
+}
+
+func credential(header, scheme string) (string, bool) {
+	s, value, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(s, scheme) {
+		return "", false
+	}
+	value = strings.TrimSpace(value)
+	return value, value != ""
+}
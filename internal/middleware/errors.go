@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"elk-stack-user/internal/apperror"
+	"elk-stack-user/internal/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// ProblemContentType RFC 7807 hata yanıtlarının media type'ı
+const ProblemContentType = "application/problem+json"
+
+// ErrorHandler handler'ların c.Error ile bıraktığı son hatayı RFC 7807
+// problem+json yanıtına çevirir.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		writeProblem(c, c.Errors.Last().Err)
+	}
+}
+
+// abortWithProblem middleware'lerin reddettiği istekleri handler hatalarıyla aynı
+// problem+json formatında hemen sonlandırır
+func abortWithProblem(c *gin.Context, err error) {
+	c.Abort()
+	writeProblem(c, err)
+}
+
+// writeProblem hatayı problem+json olarak yazar. Status kodu apperror türünden gelir; instance
+// request ID'dir. Bilinmeyen hatalar loglanır ve detayı client'a gösterilmeden 500 döner.
+func writeProblem(c *gin.Context, err error) {
+	status := apperror.Status(err)
+	problem := gin.H{
+		"type":     "about:blank",
+		"title":    http.StatusText(status),
+		"status":   status,
+		"detail":   err.Error(),
+		"instance": c.GetString("request_id"),
+	}
+
+	if status == http.StatusInternalServerError {
+		logger.Logger.Error("Unhandled request error",
+			logger.RequestID(c.GetString("request_id")),
+			logger.Method(c.Request.Method),
+			logger.Path(c.Request.URL.Path),
+			logger.Error(err),
+		)
+		problem["detail"] = "An unexpected error occurred"
+	} else {
+		var appErr *apperror.Error
+		if errors.As(err, &appErr) && len(appErr.Fields) > 0 {
+			problem["errors"] = appErr.Fields
+		}
+		// Detailer alanları (violations, expires_at ...) RFC 7807 extension member'ı olarak eklenir
+		var detailer apperror.Detailer
+		if errors.As(err, &detailer) {
+			for key, value := range detailer.Details() {
+				if _, reserved := problem[key]; !reserved {
+					problem[key] = value
+				}
+			}
+		}
+		var retrier apperror.Retrier
+		if errors.As(err, &retrier) {
+			c.Header("Retry-After", strconv.FormatInt(retrier.RetryAfter(time.Now()), 10))
+		}
+	}
+
+	c.Header("Content-Type", ProblemContentType)
+	c.JSON(status, problem)
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"elk-stack-user/internal/apperror"
+	"elk-stack-user/internal/ipacl"
+	"elk-stack-user/internal/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// DenyIPs rejects requests whose client IP is on the deny list.
+// c.ClientIP() only honors forwarding headers from trusted proxies.
+func DenyIPs(acl *ipacl.ACL) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if acl.Denied(c.ClientIP()) {
+			logger.Logger.Warn("Request blocked by IP deny list",
+				logger.SecurityEvent("ip_denied"),
+				logger.ClientIP(c.ClientIP()),
+				logger.String("path", c.Request.URL.Path),
+			)
+			abortWithProblem(c, apperror.Forbidden("access denied"))
+			return
+		}
+		c.Next()
+	}
+}
+
+// AllowAdminIPs rejects requests from outside the admin allow list, if one is configured.
+func AllowAdminIPs(acl *ipacl.ACL) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !acl.AdminAllowed(c.ClientIP()) {
+			logger.Logger.Warn("Admin request blocked by IP allow list",
+				logger.SecurityEvent("admin_ip_not_allowed"),
+				logger.ClientIP(c.ClientIP()),
+				logger.String("path", c.Request.URL.Path),
+			)
+			abortWithProblem(c, apperror.Forbidden("access denied"))
+			return
+		}
+		c.Next()
+	}
+}
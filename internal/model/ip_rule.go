@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+)
+
+// IPRule veritabanında tutulan IP erişim kuralı.
+// deny kuralları tüm istekleri, allow kuralları sadece /admin route'larını etkiler.
+type IPRule struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	CIDR      string     `json:"cidr" gorm:"not null"`
+	Action    string     `json:"action" gorm:"index;not null"`
+	Note      string     `json:"note"`
+	CreatedBy *uint      `json:"created_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+const (
+	IPRuleAllow = "allow"
+	IPRuleDeny  = "deny"
+)
+
+type CreateIPRuleRequest struct {
+	CIDR   string `json:"cidr" binding:"required"`
+	Action string `json:"action" binding:"required,oneof=allow deny"`
+	Note   string `json:"note"`
+	// Duration boşsa kural süresizdir
+	Duration string `json:"duration"`
+}
@@ -0,0 +1,15 @@
+package model
+
+import (
+	"time"
+)
+
+// RecoveryCode TOTP cihazı kaybolduğunda kullanılan tek kullanımlık kurtarma kodu.
+// Kodun kendisi saklanmaz, sadece SHA-256 hash'i tutulur.
+type RecoveryCode struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"index;not null"`
+	CodeHash  string     `json:"-" gorm:"index;not null"`
+	CreatedAt time.Time  `json:"created_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+}
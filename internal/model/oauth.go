@@ -0,0 +1,117 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// OAuthClient OIDC provider'a kayıtlı bir uygulama.
+// Public client'ların (SPA, mobil) secret'ı yoktur ve authorization code akışında PKCE zorunludur.
+type OAuthClient struct {
+	ID           uint       `json:"id" gorm:"primaryKey"`
+	ClientID     string     `json:"client_id" gorm:"uniqueIndex;not null"`
+	SecretHash   string     `json:"-"`
+	Name         string     `json:"name" gorm:"not null"`
+	RedirectURIs string     `json:"-" gorm:"not null;default:''"` // boşlukla ayrılmış
+	GrantTypes   string     `json:"-" gorm:"not null;default:''"` // boşlukla ayrılmış
+	Scopes       string     `json:"-" gorm:"not null;default:''"` // boşlukla ayrılmış, izin verilen scope'lar
+	Public       bool       `json:"public"`
+	CreatedBy    *uint      `json:"created_by,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+}
+
+func (c *OAuthClient) RedirectURIList() []string { return strings.Fields(c.RedirectURIs) }
+func (c *OAuthClient) GrantTypeList() []string   { return strings.Fields(c.GrantTypes) }
+func (c *OAuthClient) ScopeList() []string       { return strings.Fields(c.Scopes) }
+
+// OAuthAuthorizationCode tek kullanımlık authorization code, sadece hash'i saklanır
+type OAuthAuthorizationCode struct {
+	ID                  uint   `gorm:"primaryKey"`
+	CodeHash            string `gorm:"uniqueIndex;not null"`
+	ClientID            string `gorm:"index;not null"`
+	UserID              uint   `gorm:"not null"`
+	RedirectURI         string `gorm:"not null"`
+	Scope               string
+	Nonce               string
+	CodeChallenge       string `gorm:"not null"`
+	CodeChallengeMethod string `gorm:"not null"`
+	AuthTime            time.Time
+	CreatedAt           time.Time
+	ExpiresAt           time.Time
+	UsedAt              *time.Time
+}
+
+// OAuthAccessToken opak access token, introspection ve revocation için veritabanında tutulur.
+// Client credentials token'larında UserID nil'dir.
+type OAuthAccessToken struct {
+	ID        uint   `gorm:"primaryKey"`
+	TokenHash string `gorm:"uniqueIndex;not null"`
+	ClientID  string `gorm:"index;not null"`
+	UserID    *uint  `gorm:"index"`
+	Scope     string
+	CreatedAt time.Time
+	ExpiresAt time.Time `gorm:"index"`
+	RevokedAt *time.Time
+}
+
+// OAuth grant tipleri
+const (
+	GrantTypeAuthorizationCode = "authorization_code"
+	GrantTypeClientCredentials = "client_credentials"
+)
+
+type CreateOAuthClientRequest struct {
+	Name         string   `json:"name" binding:"required"`
+	RedirectURIs []string `json:"redirect_uris"`
+	GrantTypes   []string `json:"grant_types" binding:"required,min=1,dive,oneof=authorization_code client_credentials"`
+	Scopes       []string `json:"scopes"`
+	Public       bool     `json:"public"`
+}
+
+type OAuthClientResponse struct {
+	ID           uint       `json:"id"`
+	ClientID     string     `json:"client_id"`
+	Name         string     `json:"name"`
+	RedirectURIs []string   `json:"redirect_uris"`
+	GrantTypes   []string   `json:"grant_types"`
+	Scopes       []string   `json:"scopes"`
+	Public       bool       `json:"public"`
+	CreatedAt    time.Time  `json:"created_at"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	// ClientSecret sadece oluşturma cevabında döner
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+// AuthorizeRequest /oauth/authorize parametreleri
+type AuthorizeRequest struct {
+	ResponseType        string `form:"response_type"`
+	ClientID            string `form:"client_id"`
+	RedirectURI         string `form:"redirect_uri"`
+	Scope               string `form:"scope"`
+	State               string `form:"state"`
+	Nonce               string `form:"nonce"`
+	CodeChallenge       string `form:"code_challenge"`
+	CodeChallengeMethod string `form:"code_challenge_method"`
+}
+
+// TokenResponse RFC 6749 token cevabı
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	Scope       string `json:"scope,omitempty"`
+	IDToken     string `json:"id_token,omitempty"`
+}
+
+// IntrospectionResponse RFC 7662 cevabı, token geçersizse sadece active=false döner
+type IntrospectionResponse struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Subject   string `json:"sub,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+	Issuer    string `json:"iss,omitempty"`
+}
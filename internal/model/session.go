@@ -0,0 +1,18 @@
+package model
+
+import (
+	"time"
+)
+
+// Session bir login sonrası verilen bearer token'ı temsil eder.
+// Token'ın kendisi saklanmaz, sadece SHA-256 hash'i tutulur.
+type Session struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"index;not null"`
+	TokenHash string     `json:"-" gorm:"uniqueIndex;not null"`
+	IPAddress string     `json:"ip_address"`
+	UserAgent string     `json:"user_agent"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"index"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
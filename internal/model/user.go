@@ -5,48 +5,86 @@ import (
 	"gorm.io/gorm"
 )
 
+// User. Username ve Email tekilliği silinmemiş satırlar için lower(...) üzerindeki
+// partial unique index'lerle sağlanır (database/migrations); büyük/küçük harf farkı
+// ayrı hesap sayılmaz ve soft-delete edilmiş hesaplar isimleri bloklamaz.
+// Version her yazmada bir artar; optimistic concurrency ve ETag bunu kullanır.
 type User struct {
-	ID        uint           `json:"id" gorm:"primaryKey"`
-	Username  string         `json:"username" gorm:"uniqueIndex;not null"`
-	Email     string         `json:"email" gorm:"uniqueIndex;not null"`
-	Password  string         `json:"-" gorm:"not null"` // JSON'da gösterilmez
-	FirstName string         `json:"first_name"`
-	LastName  string         `json:"last_name"`
-	Age       int            `json:"age"`
-	IsActive  bool           `json:"is_active" gorm:"default:true"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	Username        string     `json:"username" gorm:"not null"`
+	Email           string     `json:"email" gorm:"not null"`
+	Password        string     `json:"-" gorm:"not null"` // JSON'da gösterilmez
+	FirstName       string     `json:"first_name"`
+	LastName        string     `json:"last_name"`
+	Age             int        `json:"age"`
+	IsActive        bool       `json:"is_active" gorm:"default:true"`
+	Role            string     `json:"role" gorm:"not null;default:user"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	Version         uint       `json:"version" gorm:"not null;default:1"`
+	// TOTP 2FA: TOTPEnabledAt nil ise secret sadece bekleyen bir kayda aittir
+	TOTPSecret    string         `json:"-"`
+	TOTPEnabledAt *time.Time     `json:"-"`
+	TOTPLastStep  int64          `json:"-" gorm:"not null;default:0"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
 }
 
 type CreateUserRequest struct {
 	Username  string `json:"username" binding:"required"`
 	Email     string `json:"email" binding:"required,email"`
-	Password  string `json:"password" binding:"required,min=6"`
+	Password  string `json:"password" binding:"required"`
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
 	Age       int    `json:"age"`
 }
 
-type UpdateUserRequest struct {
-	Username  *string `json:"username"`
-	Email     *string `json:"email"`
-	FirstName *string `json:"first_name"`
-	LastName  *string `json:"last_name"`
-	Age       *int    `json:"age"`
-	IsActive  *bool   `json:"is_active"`
+// ReplaceUserRequest kullanıcının düzenlenebilir temsili. PUT bunu tam olarak
+// yerine koyar (gönderilmeyen opsiyonel alanlar boşaltılır), PATCH ise patch'i
+// bunun JSON'una uygulayıp sonucu aynı kurallarla doğrular. is_active yanlışlıkla
+// hesabı pasifleştirmesin diye zorunludur.
+type ReplaceUserRequest struct {
+	Username  string `json:"username" binding:"required"`
+	Email     string `json:"email" binding:"required,email"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Age       int    `json:"age" binding:"min=0"`
+	IsActive  *bool  `json:"is_active" binding:"required"`
 }
 
 type UserResponse struct {
-	ID        uint      `json:"id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	FirstName string    `json:"first_name"`
-	LastName  string    `json:"last_name"`
-	Age       int       `json:"age"`
-	IsActive  bool      `json:"is_active"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID              uint       `json:"id"`
+	Username        string     `json:"username"`
+	Email           string     `json:"email"`
+	FirstName       string     `json:"first_name"`
+	LastName        string     `json:"last_name"`
+	Age             int        `json:"age"`
+	IsActive        bool       `json:"is_active"`
+	Role            string     `json:"role"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	MFAEnabled      bool       `json:"mfa_enabled"`
+	Version         uint       `json:"version"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// Precondition If-Match başlığındaki versiyonlar. nil koşulsuz yazma demektir;
+// boş liste (sadece weak veya geçersiz tag'ler) hiçbir versiyonla eşleşmez.
+type Precondition struct {
+	Versions []uint
+}
+
+// Allows yazmanın verilen güncel versiyon üzerinde yapılıp yapılamayacağı
+func (p *Precondition) Allows(version uint) bool {
+	if p == nil {
+		return true
+	}
+	for _, v := range p.Versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
 }
 
 // Login DTOs
@@ -55,24 +93,126 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+// LoginResponse MFA gerekiyorsa User ve Token yerine MFAToken döner
 type LoginResponse struct {
-	User  UserResponse `json:"user"`
-	Token string       `json:"token,omitempty"`
+	User        *UserResponse `json:"user,omitempty"`
+	Token       string        `json:"token,omitempty"`
+	MFARequired bool          `json:"mfa_required,omitempty"`
+	MFAToken    string        `json:"mfa_token,omitempty"`
+}
+
+type MFALoginRequest struct {
+	MFAToken     string `json:"mfa_token" binding:"required"`
+	Code         string `json:"code" binding:"required_without=RecoveryCode"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+// Password DTOs
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// Email verification DTOs
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// MFA DTOs
+type TOTPEnrollmentResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+	QRCodePNG  []byte `json:"qr_code_png"` // base64
+}
+
+type TOTPConfirmRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type TOTPConfirmResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type TOTPDisableRequest struct {
+	Password string `json:"password" binding:"required"`
 }
 
 // Ban system structures
 type LoginAttempt struct {
-	Username   string    `json:"username"`
-	IPAddress  string    `json:"ip_address"`
+	Username   string    `json:"username" gorm:"index"`
+	IPAddress  string    `json:"ip_address" gorm:"index"`
 	Success    bool      `json:"success"`
-	Timestamp  time.Time `json:"timestamp"`
+	Timestamp  time.Time `json:"timestamp" gorm:"index"`
 	UserAgent  string    `json:"user_agent"`
+	// Blocked aktif bir ban sırasında yapılan deneme, ban eşiklerine sayılmaz
+	Blocked bool `json:"blocked" gorm:"not null;default:false"`
 }
 
+// BanRecord Type alanına göre sadece ilgili anahtar(lar)ı bloklar:
+// username ban'ı her IP'den, ip ban'ı her kullanıcı için, username_ip ban'ı sadece o çifti
 type BanRecord struct {
-	Username   string    `json:"username"`
-	IPAddress  string    `json:"ip_address"`
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	Type       string    `json:"type" gorm:"index;not null;default:username_ip"`
+	Username   string    `json:"username" gorm:"index"`
+	IPAddress  string    `json:"ip_address" gorm:"index"`
 	BannedAt   time.Time `json:"banned_at"`
-	ExpiresAt  time.Time `json:"expires_at"`
+	ExpiresAt  time.Time `json:"expires_at" gorm:"index"`
 	Reason     string    `json:"reason"`
+	// AttemptsSince otomatik banlarda banı tetikleyen denemelerin pencere başlangıcı, manuel banlarda nil
+	AttemptsSince *time.Time `json:"attempts_since,omitempty"`
+	// CreatedBy manuel banı açan admin, otomatik banlarda nil
+	CreatedBy *uint      `json:"created_by,omitempty"`
+	LiftedAt  *time.Time `json:"lifted_at,omitempty" gorm:"index"`
+	LiftedBy  *uint      `json:"lifted_by,omitempty"`
+}
+
+// BanFilter admin ban listesi filtreleri, boş alanlar filtrelenmez
+type BanFilter struct {
+	Type     string
+	Username string
+	IP       string
+	CIDR     string
+	Reason   string
+	// IncludeInactive true ise süresi dolmuş ve kaldırılmış banlar da listelenir
+	IncludeInactive bool
+}
+
+type CreateBanRequest struct {
+	Type      string `json:"type" binding:"required,oneof=username ip username_ip"`
+	Username  string `json:"username"`
+	IPAddress string `json:"ip_address"`
+	Duration  string `json:"duration" binding:"required"` // Go duration, ör. "2h"
+	Reason    string `json:"reason" binding:"required"`
+}
+
+// BanDetailResponse ban kaydı ve onu tetikleyen başarısız login denemeleri
+type BanDetailResponse struct {
+	Ban      *BanRecord     `json:"ban"`
+	Attempts []LoginAttempt `json:"attempts"`
+}
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+const (
+	BanTypeUsername   = "username"
+	BanTypeIP         = "ip"
+	BanTypeUsernameIP = "username_ip"
+)
+
+// DeletedUserResponse admin'in gördüğü soft-delete edilmiş kullanıcı
+type DeletedUserResponse struct {
+	*UserResponse
+	DeletedAt time.Time `json:"deleted_at"`
 }
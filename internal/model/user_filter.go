@@ -0,0 +1,162 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// UserFilter GET /users listesinin filtreleri. Nil/boş alanlar filtrelenmez.
+type UserFilter struct {
+	IsActive       *bool
+	MinAge         *int
+	MaxAge         *int
+	CreatedAfter   *time.Time
+	CreatedBefore  *time.Time
+	UsernamePrefix string
+	EmailPrefix    string
+	// Query ad ve soyad üzerinde serbest metin araması, her kelime eşleşmeli
+	Query string
+	Sort  []SortField
+}
+
+// HasConditions satırları daraltan bir filtre olup olmadığını döner, sıralama sayılmaz
+func (f *UserFilter) HasConditions() bool {
+	return f != nil && (f.IsActive != nil || f.MinAge != nil || f.MaxAge != nil ||
+		f.CreatedAfter != nil || f.CreatedBefore != nil ||
+		f.UsernamePrefix != "" || f.EmailPrefix != "" || strings.TrimSpace(f.Query) != "")
+}
+
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// UserSortColumns sıralamaya izin verilen alanlar ve karşılık gelen kolonlar.
+// ORDER BY sadece bu map'ten gelen kolonlarla kurulur.
+var UserSortColumns = map[string]string{
+	"id":         "id",
+	"username":   "username",
+	"email":      "email",
+	"first_name": "first_name",
+	"last_name":  "last_name",
+	"age":        "age",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
+// maxSortFields bir istekteki en fazla sıralama alanı
+const maxSortFields = 3
+
+// ParseUserSort "sort=-created_at,username" formatını parse eder, '-' azalan sıradır
+func ParseUserSort(value string) ([]SortField, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	var fields []SortField
+	seen := map[string]bool{}
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		desc := strings.HasPrefix(part, "-")
+		name := strings.TrimPrefix(strings.TrimPrefix(part, "-"), "+")
+		if _, ok := UserSortColumns[name]; !ok {
+			return nil, fmt.Errorf("unsupported sort field %q", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate sort field %q", name)
+		}
+		seen[name] = true
+		fields = append(fields, SortField{Field: name, Desc: desc})
+	}
+	if len(fields) > maxSortFields {
+		return nil, fmt.Errorf("at most %d sort fields are allowed", maxSortFields)
+	}
+	return fields, nil
+}
+
+// UserSortKeys sıralamayı deterministik yapan anahtar listesini döner: istenen alanlar ve sonda id
+func UserSortKeys(sort []SortField) []SortField {
+	keys := make([]SortField, 0, len(sort)+1)
+	for _, field := range sort {
+		if _, ok := UserSortColumns[field.Field]; ok {
+			keys = append(keys, field)
+			if field.Field == "id" {
+				return keys
+			}
+		}
+	}
+	return append(keys, SortField{Field: "id"})
+}
+
+// UserCursor keyset pagination anahtarı: UserSortKeys sırasıyla son görülen satırın değerleri
+type UserCursor struct {
+	Values []interface{}
+	// Backward true ise cursor'dan önceki sayfa istenir
+	Backward bool
+}
+
+// SortValue sıralama alanının kullanıcıdaki değerini döner
+func (u *User) SortValue(field string) interface{} {
+	switch field {
+	case "id":
+		return u.ID
+	case "username":
+		return u.Username
+	case "email":
+		return u.Email
+	case "first_name":
+		return u.FirstName
+	case "last_name":
+		return u.LastName
+	case "age":
+		return u.Age
+	case "created_at":
+		return u.CreatedAt
+	case "updated_at":
+		return u.UpdatedAt
+	}
+	return nil
+}
+
+// Total count modları
+const (
+	TotalNone     = ""
+	TotalExact    = "exact"
+	TotalEstimate = "estimate"
+)
+
+// UserListRequest cursor tabanlı liste isteği
+type UserListRequest struct {
+	PageSize int
+	Cursor   string
+	Total    string
+}
+
+// UserPage cursor tabanlı liste sonucu. Total sadece istendiğinde dolu döner.
+type UserPage struct {
+	Users          []*UserResponse `json:"users"`
+	NextCursor     string          `json:"next_cursor,omitempty"`
+	PrevCursor     string          `json:"prev_cursor,omitempty"`
+	Total          *int64          `json:"total,omitempty"`
+	TotalEstimated bool            `json:"total_estimated,omitempty"`
+}
+
+// UserSearchResult repository'nin döndürdüğü skorlu arama sonucu.
+// Rank full-text eşleşmesinden, Similarity trigram benzerliğinden gelir.
+type UserSearchResult struct {
+	User       *User
+	Rank       float64
+	Similarity float64
+	Highlight  string
+}
+
+// UserSearchHit GET /users/search sonuç elemanı. Highlight HTML-escape edilmiştir,
+// eşleşen kelimeler <mark> ile işaretlenir.
+type UserSearchHit struct {
+	User       *UserResponse `json:"user"`
+	Score      float64       `json:"score"`
+	Rank       float64       `json:"rank"`
+	Similarity float64       `json:"similarity"`
+	Highlight  string        `json:"highlight"`
+}
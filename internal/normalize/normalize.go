@@ -0,0 +1,88 @@
+// Package normalize username ve email değerlerini saklamadan ve aramadan önce
+// tek bir kanonik forma getirir. Karşılaştırmalar büyük/küçük harf duyarsızdır
+// (lower() üzerinde unique index), saklanan değer kullanıcının yazdığı harf
+// büyüklüğünü korur.
+package normalize
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/idna"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	UsernameMinLength = 3
+	UsernameMaxLength = 32
+)
+
+var (
+	ErrInvalidUsername = errors.New("invalid username")
+	ErrInvalidEmail    = errors.New("invalid email")
+)
+
+// Text NFKC normalizasyonu uygular ve baştaki/sondaki boşlukları atar.
+// Tam genişlikli karakterler ve uyumluluk varyantları (ör. "ｅ", "ﬁ") tek forma iner.
+func Text(value string) string {
+	return strings.TrimSpace(norm.NFKC.String(value))
+}
+
+// Username username'i normalize eder ve karakter kurallarını uygular:
+// 3-32 karakter, ASCII harf, rakam, '.', '_' ve '-'; harf veya rakamla başlayıp biter,
+// iki ayırıcı yan yana gelemez.
+func Username(value string) (string, error) {
+	username := Text(value)
+	if n := len(username); n < UsernameMinLength || n > UsernameMaxLength {
+		return "", fmt.Errorf("%w: must be %d-%d characters long", ErrInvalidUsername, UsernameMinLength, UsernameMaxLength)
+	}
+
+	prevSeparator := true // ilk karakter ayırıcı olamaz
+	for _, r := range username {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			prevSeparator = false
+		case r == '.' || r == '_' || r == '-':
+			if prevSeparator {
+				return "", fmt.Errorf("%w: '.', '_' and '-' cannot start the name or follow each other", ErrInvalidUsername)
+			}
+			prevSeparator = true
+		default:
+			return "", fmt.Errorf("%w: only letters a-z, digits, '.', '_' and '-' are allowed", ErrInvalidUsername)
+		}
+	}
+	if prevSeparator {
+		return "", fmt.Errorf("%w: cannot end with '.', '_' or '-'", ErrInvalidUsername)
+	}
+	return username, nil
+}
+
+// Email adresi normalize eder: NFKC, trim ve domain'in IDNA (punycode) ASCII
+// formuna küçük harfle çevrilmesi. Local part'ın harf büyüklüğü korunur.
+func Email(value string) (string, error) {
+	email := Text(value)
+	at := strings.LastIndex(email, "@")
+	if at <= 0 || at == len(email)-1 {
+		return "", fmt.Errorf("%w: must be of the form local@domain", ErrInvalidEmail)
+	}
+
+	domain, err := idna.Lookup.ToASCII(email[at+1:])
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid domain: %v", ErrInvalidEmail, err)
+	}
+	return email[:at] + "@" + strings.ToLower(domain), nil
+}
+
+// Lookup username veya email olabilen arama/login girdisini küçük harfli
+// karşılaştırma anahtarına çevirir. Kural ihlalleri burada hata değildir; eski
+// kurallarla açılmış hesaplar da bulunabilmelidir.
+func Lookup(value string) string {
+	lookup := Text(value)
+	if strings.Contains(lookup, "@") {
+		if email, err := Email(lookup); err == nil {
+			lookup = email
+		}
+	}
+	return strings.ToLower(lookup)
+}
@@ -0,0 +1,116 @@
+package normalize
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"  alice\t", "alice"},
+		{"ｅｘａｍｐｌｅ", "example"},
+		{"ﬁle", "file"},
+		{"e\u0301", "\u00e9"},
+		{"①", "1"},
+		{"\u00a0alice\u3000", "alice"},
+	}
+
+	for _, tt := range tests {
+		if got := Text(tt.in); got != tt.want {
+			t.Errorf("Text(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestUsername(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "Alice_01", want: "Alice_01"},
+		{in: " bob.smith ", want: "bob.smith"},
+		{in: "ａｌｉｃｅ", want: "alice"},
+		{in: "ﬁx", want: "fix"},
+		{in: "a-b_c.d", want: "a-b_c.d"},
+		{in: strings.Repeat("a", UsernameMaxLength), want: strings.Repeat("a", UsernameMaxLength)},
+		{in: "ab", wantErr: true},
+		{in: strings.Repeat("a", UsernameMaxLength+1), wantErr: true},
+		{in: "-abc", wantErr: true},
+		{in: "abc.", wantErr: true},
+		{in: "a..b", wantErr: true},
+		{in: "a._b", wantErr: true},
+		{in: "ali ce", wantErr: true},
+		{in: "çağla", wantErr: true},
+		{in: "alice@example.com", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := Username(tt.in)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidUsername) {
+				t.Errorf("Username(%q) = %q, %v; want ErrInvalidUsername", tt.in, got, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Username(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestEmail(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "Alice@Example.COM", want: "Alice@example.com"},
+		{in: "  ｕｓｅｒ@ｅｘａｍｐｌｅ.ｃｏｍ ", want: "user@example.com"},
+		{in: "user@Bücher.example", want: "user@xn--bcher-kva.example"},
+		{in: "user@xn--bcher-kva.example", want: "user@xn--bcher-kva.example"},
+		{in: "quoted@local@example.com", want: "quoted@local@example.com"},
+		{in: "no-at-sign", wantErr: true},
+		{in: "@example.com", wantErr: true},
+		{in: "user@", wantErr: true},
+		{in: "user@exa mple.com", wantErr: true},
+		{in: "user@-example.com", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := Email(tt.in)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidEmail) {
+				t.Errorf("Email(%q) = %q, %v; want ErrInvalidEmail", tt.in, got, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Email(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"  ALICE ", "alice"},
+		{"ＡＬＩＣＥ", "alice"},
+		{"Alice@Example.COM", "alice@example.com"},
+		{"Ｕｓｅｒ@Bücher.example", "user@xn--bcher-kva.example"},
+		// Eski kurallarla açılmış, artık geçersiz adlar da bulunabilmeli
+		{"Émile", "émile"},
+		{"a", "a"},
+		{"broken@", "broken@"},
+	}
+
+	for _, tt := range tests {
+		if got := Lookup(tt.in); got != tt.want {
+			t.Errorf("Lookup(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Package oidc ID token imzalama, JWKS ve PKCE yardımcılarını içerir
+package oidc
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+// Signer ID token'ları RS256 ile imzalar
+type Signer struct {
+	key   *rsa.PrivateKey
+	keyID string
+}
+
+// NewSigner RSA private key ile Signer oluşturur, kid RFC 7638 thumbprint'idir
+func NewSigner(key *rsa.PrivateKey) *Signer {
+	return &Signer{key: key, keyID: thumbprint(&key.PublicKey)}
+}
+
+// LoadSigner PEM dosyasından (PKCS#1 veya PKCS#8) RSA key okur
+func LoadSigner(path string) (*Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found in signing key file")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return NewSigner(key), nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse signing key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("signing key must be an RSA key")
+	}
+	return NewSigner(key), nil
+}
+
+// GenerateSigner geçici bir 2048 bit key üretir; restart'ta değişir, sadece development için
+func GenerateSigner() (*Signer, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	return NewSigner(key), nil
+}
+
+func (s *Signer) KeyID() string {
+	return s.keyID
+}
+
+// Sign claims'i compact serialization'da JWT olarak imzalar
+func (s *Signer) Sign(claims map[string]interface{}) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": s.keyID})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := encode(header) + "." + encode(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + encode(signature), nil
+}
+
+// JWKS public key'i JSON Web Key Set olarak döner
+func (s *Signer) JWKS() map[string]interface{} {
+	return map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"kid": s.keyID,
+			"n":   encode(s.key.PublicKey.N.Bytes()),
+			"e":   encode(big.NewInt(int64(s.key.PublicKey.E)).Bytes()),
+		}},
+	}
+}
+
+// thumbprint RFC 7638 JWK thumbprint'i
+func thumbprint(key *rsa.PublicKey) string {
+	// Alanlar sözlük sırasında olmalı: e, kty, n
+	canonical := fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`,
+		encode(big.NewInt(int64(key.E)).Bytes()), encode(key.N.Bytes()))
+	sum := sha256.Sum256([]byte(canonical))
+	return encode(sum[:])
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// VerifyPKCE RFC 7636 code_verifier'ı challenge ile karşılaştırır, sadece S256 desteklenir
+func VerifyPKCE(verifier, challenge, method string) bool {
+	if method != "S256" || len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	return subtle.ConstantTimeCompare([]byte(encode(sum[:])), []byte(challenge)) == 1
+}
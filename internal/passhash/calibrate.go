@@ -0,0 +1,55 @@
+package passhash
+
+import (
+	"time"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// calibrationRounds ölçümlerde gürültüyü azaltmak için tekrar sayısı
+const calibrationRounds = 3
+
+// maxArgon2idIterations CalibrateArgon2id'nin deneyeceği en yüksek iteration sayısı
+const maxArgon2idIterations = 64
+
+// CalibrateBcrypt tek bir hash'in target süresine ulaştığı en düşük bcrypt cost'unu bulur.
+// Hedefe ulaşılamazsa denenen son cost ve onun ölçülen süresi döner.
+func CalibrateBcrypt(target time.Duration) (int, time.Duration) {
+	for cost := bcrypt.MinCost; ; cost++ {
+		elapsed := measure(func() {
+			bcrypt.GenerateFromPassword([]byte("calibration-password"), cost)
+		})
+		// Her cost artışı süreyi yaklaşık ikiye katlar
+		if elapsed >= target || cost == bcrypt.MaxCost {
+			return cost, elapsed
+		}
+	}
+}
+
+// CalibrateArgon2id verilen memory ve parallelism ile target süresine ulaşan iteration sayısını bulur.
+// Hedefe ulaşılamazsa maxArgon2idIterations ve onun ölçülen süresi döner.
+func CalibrateArgon2id(target time.Duration, memory uint32, parallelism uint8) (Argon2idParams, time.Duration) {
+	params := DefaultArgon2idParams
+	params.Memory = memory
+	params.Parallelism = parallelism
+	salt := make([]byte, params.SaltLength)
+
+	for params.Iterations = 1; ; params.Iterations++ {
+		elapsed := measure(func() {
+			argon2.IDKey([]byte("calibration-password"), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+		})
+		if elapsed >= target || params.Iterations == maxArgon2idIterations {
+			return params, elapsed
+		}
+	}
+}
+
+// measure fonksiyonun ortalama çalışma süresini döner
+func measure(fn func()) time.Duration {
+	start := time.Now()
+	for i := 0; i < calibrationRounds; i++ {
+		fn()
+	}
+	return time.Since(start) / calibrationRounds
+}
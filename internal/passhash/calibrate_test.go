@@ -0,0 +1,29 @@
+package passhash
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestCalibrateArgon2idReturnsLastMeasuredIterations(t *testing.T) {
+	// Ulaşılamayacak bir hedef: döngü sonuna kadar gider
+	params, elapsed := CalibrateArgon2id(time.Hour, 8, 1)
+	if params.Iterations != maxArgon2idIterations {
+		t.Fatalf("Iterations = %d, want %d", params.Iterations, maxArgon2idIterations)
+	}
+	if elapsed <= 0 {
+		t.Fatalf("elapsed = %s, want the measured duration of the last iteration count", elapsed)
+	}
+}
+
+func TestCalibrateBcryptStopsAtTarget(t *testing.T) {
+	cost, elapsed := CalibrateBcrypt(time.Nanosecond)
+	if cost != bcrypt.MinCost {
+		t.Fatalf("cost = %d, want the minimum cost for a trivial target", cost)
+	}
+	if elapsed <= 0 {
+		t.Fatalf("elapsed = %s, want a positive measurement", elapsed)
+	}
+}
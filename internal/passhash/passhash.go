@@ -0,0 +1,126 @@
+// Package passhash versiyonlu şifre hash'leme: bcrypt ve Argon2id (PHC formatı).
+// Hash string'inin prefix'i algoritmayı belirler, böylece eski hash'ler doğrulanmaya
+// devam ederken yeni hash'ler güncel algoritma ve parametrelerle üretilir.
+package passhash
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var ErrUnknownHashFormat = errors.New("unknown password hash format")
+
+// Hasher tek bir algoritma için hash üretir ve doğrular
+type Hasher interface {
+	// ID hash string'indeki algoritma tanımlayıcısı (ör. "bcrypt", "argon2id")
+	ID() string
+	Hash(password string) (string, error)
+	Verify(password, encoded string) (bool, error)
+	// Matches hash'in bu hasher'a ait olup olmadığını prefix'ten anlar
+	Matches(encoded string) bool
+	// NeedsRehash hash'in parametreleri güncel config'den zayıfsa true döner
+	NeedsRehash(encoded string) bool
+}
+
+// Manager güncel hasher ile hash üretir, bilinen tüm algoritmalarla doğrular
+type Manager struct {
+	current Hasher
+	known   []Hasher
+}
+
+func NewManager(current Hasher, legacy ...Hasher) *Manager {
+	return &Manager{current: current, known: append([]Hasher{current}, legacy...)}
+}
+
+type Config struct {
+	Algorithm  string // bcrypt veya argon2id
+	BcryptCost int
+	Argon2id   Argon2idParams
+}
+
+func NewConfig() *Config {
+	return &Config{
+		Algorithm:  strings.ToLower(getEnv("PASSWORD_HASH_ALGORITHM", "bcrypt")),
+		BcryptCost: getEnvInt("BCRYPT_COST", bcrypt.DefaultCost),
+		Argon2id: Argon2idParams{
+			Memory:      uint32(getEnvInt("ARGON2_MEMORY_KIB", int(DefaultArgon2idParams.Memory))),
+			Iterations:  uint32(getEnvInt("ARGON2_ITERATIONS", int(DefaultArgon2idParams.Iterations))),
+			Parallelism: uint8(getEnvInt("ARGON2_PARALLELISM", int(DefaultArgon2idParams.Parallelism))),
+			SaltLength:  DefaultArgon2idParams.SaltLength,
+			KeyLength:   DefaultArgon2idParams.KeyLength,
+		},
+	}
+}
+
+// New config'deki algoritmayı güncel kabul eden, diğerlerini legacy olarak doğrulayan bir Manager döner
+func New(config *Config) (*Manager, error) {
+	if config.BcryptCost < bcrypt.MinCost || config.BcryptCost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("invalid bcrypt cost: %d", config.BcryptCost)
+	}
+	bcryptHasher := NewBcryptHasher(config.BcryptCost)
+	argon2idHasher := NewArgon2idHasher(config.Argon2id)
+
+	switch config.Algorithm {
+	case "bcrypt":
+		return NewManager(bcryptHasher, argon2idHasher), nil
+	case "argon2id":
+		return NewManager(argon2idHasher, bcryptHasher), nil
+	default:
+		return nil, fmt.Errorf("unknown password hash algorithm: %s", config.Algorithm)
+	}
+}
+
+// Default bcrypt.DefaultCost ile bcrypt kullanan Manager
+func Default() *Manager {
+	return NewManager(NewBcryptHasher(bcrypt.DefaultCost), NewArgon2idHasher(DefaultArgon2idParams))
+}
+
+func (m *Manager) Hash(password string) (string, error) {
+	return m.current.Hash(password)
+}
+
+func (m *Manager) Verify(password, encoded string) (bool, error) {
+	for _, h := range m.known {
+		if h.Matches(encoded) {
+			return h.Verify(password, encoded)
+		}
+	}
+	return false, ErrUnknownHashFormat
+}
+
+// NeedsRehash hash eski bir algoritmayla veya zayıf parametrelerle üretildiyse true döner
+func (m *Manager) NeedsRehash(encoded string) bool {
+	if !m.current.Matches(encoded) {
+		return true
+	}
+	return m.current.NeedsRehash(encoded)
+}
+
+// Algorithm hash'in algoritma adını döner (loglama için)
+func (m *Manager) Algorithm(encoded string) string {
+	for _, h := range m.known {
+		if h.Matches(encoded) {
+			return h.ID()
+		}
+	}
+	return "unknown"
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	if i, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return i
+	}
+	return defaultValue
+}
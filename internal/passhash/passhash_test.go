@@ -0,0 +1,40 @@
+package passhash
+
+import "testing"
+
+// Benchmark'lar NewConfig'i kullanır, böylece BCRYPT_COST ve ARGON2_* ile ayarlanan
+// parametrelerin bu makinedeki maliyeti ölçülür:
+//
+//	ARGON2_MEMORY_KIB=65536 ARGON2_ITERATIONS=3 go test -run '^$' -bench . ./internal/passhash
+
+const benchmarkPassword = "correct horse battery staple"
+
+func benchmarkHasher(b *testing.B, h Hasher) {
+	encoded, err := h.Hash(benchmarkPassword)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("Hash", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := h.Hash(benchmarkPassword); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Verify", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if ok, err := h.Verify(benchmarkPassword, encoded); err != nil || !ok {
+				b.Fatalf("Verify = %v, %v", ok, err)
+			}
+		}
+	})
+}
+
+func BenchmarkBcrypt(b *testing.B) {
+	benchmarkHasher(b, NewBcryptHasher(NewConfig().BcryptCost))
+}
+
+func BenchmarkArgon2id(b *testing.B) {
+	benchmarkHasher(b, NewArgon2idHasher(NewConfig().Argon2id))
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"elk-stack-user/internal/model"
+	"gorm.io/gorm"
+)
+
+// BanRepository admin ban yönetimi için sorgular
+type BanRepository interface {
+	List(ctx context.Context, filter *model.BanFilter, limit, offset int) ([]*model.BanRecord, int64, error)
+	GetByID(ctx context.Context, id uint) (*model.BanRecord, error)
+	Create(ctx context.Context, ban *model.BanRecord) error
+	// Lift aktif bir banı erken kaldırır, ban zaten kaldırılmışsa gorm.ErrRecordNotFound döner
+	Lift(ctx context.Context, id, liftedBy uint) error
+	// TriggeringAttempts otomatik bir banı tetikleyen başarısız login denemelerini döner
+	TriggeringAttempts(ctx context.Context, ban *model.BanRecord) ([]model.LoginAttempt, error)
+}
+
+type banRepository struct {
+	db *gorm.DB
+}
+
+func NewBanRepository(db *gorm.DB) BanRepository {
+	return &banRepository{db: db}
+}
+
+func (r *banRepository) List(ctx context.Context, filter *model.BanFilter, limit, offset int) ([]*model.BanRecord, int64, error) {
+	query := r.db.WithContext(ctx).Model(&model.BanRecord{})
+
+	if !filter.IncludeInactive {
+		query = query.Where("expires_at > ? AND lifted_at IS NULL", time.Now())
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.Username != "" {
+		query = query.Where("username = ?", filter.Username)
+	}
+	if filter.IP != "" {
+		query = query.Where("ip_address = ?", filter.IP)
+	}
+	if filter.CIDR != "" {
+		// Boş ip_address inet'e cast edilemez, CASE ile korunur
+		query = query.Where("(CASE WHEN ip_address <> '' THEN ip_address::inet END) <<= ?::cidr", filter.CIDR)
+	}
+	if filter.Reason != "" {
+		query = query.Where("reason ILIKE ? ESCAPE '\\'", "%"+escapeLike(filter.Reason)+"%")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var bans []*model.BanRecord
+	err := query.Order("banned_at DESC").Limit(limit).Offset(offset).Find(&bans).Error
+	return bans, total, err
+}
+
+func (r *banRepository) GetByID(ctx context.Context, id uint) (*model.BanRecord, error) {
+	var ban model.BanRecord
+	if err := r.db.WithContext(ctx).First(&ban, id).Error; err != nil {
+		return nil, err
+	}
+	return &ban, nil
+}
+
+func (r *banRepository) Create(ctx context.Context, ban *model.BanRecord) error {
+	return r.db.WithContext(ctx).Create(ban).Error
+}
+
+func (r *banRepository) Lift(ctx context.Context, id, liftedBy uint) error {
+	result := r.db.WithContext(ctx).Model(&model.BanRecord{}).
+		Where("id = ? AND lifted_at IS NULL", id).
+		Updates(map[string]interface{}{"lifted_at": time.Now(), "lifted_by": liftedBy})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *banRepository) TriggeringAttempts(ctx context.Context, ban *model.BanRecord) ([]model.LoginAttempt, error) {
+	if ban.AttemptsSince == nil {
+		return []model.LoginAttempt{}, nil
+	}
+
+	var attempts []model.LoginAttempt
+	err := banKeyScope(r.db.WithContext(ctx).Model(&model.LoginAttempt{}), ban.Type, ban.Username, ban.IPAddress).
+		Where("success = ? AND blocked = ? AND timestamp > ? AND timestamp <= ?", false, false, *ban.AttemptsSince, ban.BannedAt).
+		Order("timestamp ASC").
+		Find(&attempts).Error
+	return attempts, err
+}
+
+// escapeLike LIKE/ILIKE pattern'inde kullanıcı girdisindeki joker karakterleri kaçırır
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"errors"
+
+	"elk-stack-user/internal/apperror"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// pgUniqueViolation Postgres unique_violation SQLSTATE kodu
+const pgUniqueViolation = "23505"
+
+// Unique index ihlallerinin domain karşılıkları
+var (
+	ErrUsernameExists = &apperror.Error{
+		Kind:    apperror.ErrConflict,
+		Message: "username already exists",
+		Fields:  []apperror.FieldError{{Field: "username", Rule: "unique", Message: "is already taken"}},
+	}
+	ErrEmailExists = &apperror.Error{
+		Kind:    apperror.ErrConflict,
+		Message: "email already exists",
+		Fields:  []apperror.FieldError{{Field: "email", Rule: "unique", Message: "is already registered"}},
+	}
+)
+
+// ErrStaleVersion versiyonlu bir yazmada satır okunduktan sonra başka bir istekle değişmiş
+var ErrStaleVersion = apperror.PreconditionFailed("user has been modified by another request")
+
+// uniqueConstraintErrors constraint/index adından dönecek conflict hatası.
+// Yeni bir unique index eklendiğinde buraya da eklenmeli, aksi halde genel bir conflict döner.
+var uniqueConstraintErrors = map[string]error{
+	"idx_users_username_lower": ErrUsernameExists,
+	"idx_users_email_lower":    ErrEmailExists,
+}
+
+// translateError gorm ve Postgres hatalarını apperror tiplerine çevirir.
+// Orijinal hata zincirde kalır, errors.Is(err, gorm.ErrRecordNotFound) çalışmaya devam eder.
+func translateError(err error, notFound string) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperror.Wrap(apperror.ErrNotFound, notFound, err)
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		if mapped, ok := uniqueConstraintErrors[pgErr.ConstraintName]; ok {
+			return mapped
+		}
+		return apperror.Wrap(apperror.ErrConflict, "resource already exists", err)
+	}
+	return err
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"elk-stack-user/internal/model"
+	"gorm.io/gorm"
+)
+
+type IPRuleRepository interface {
+	// ListActive süresi dolmamış tüm kuralları döner
+	ListActive(ctx context.Context) ([]*model.IPRule, error)
+	Create(ctx context.Context, rule *model.IPRule) error
+	// Delete kural yoksa gorm.ErrRecordNotFound döner
+	Delete(ctx context.Context, id uint) (*model.IPRule, error)
+}
+
+type ipRuleRepository struct {
+	db *gorm.DB
+}
+
+func NewIPRuleRepository(db *gorm.DB) IPRuleRepository {
+	return &ipRuleRepository{db: db}
+}
+
+func (r *ipRuleRepository) ListActive(ctx context.Context) ([]*model.IPRule, error) {
+	var rules []*model.IPRule
+	err := r.db.WithContext(ctx).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		Order("id ASC").
+		Find(&rules).Error
+	return rules, err
+}
+
+func (r *ipRuleRepository) Create(ctx context.Context, rule *model.IPRule) error {
+	return r.db.WithContext(ctx).Create(rule).Error
+}
+
+func (r *ipRuleRepository) Delete(ctx context.Context, id uint) (*model.IPRule, error) {
+	var rule model.IPRule
+	if err := r.db.WithContext(ctx).First(&rule, id).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.WithContext(ctx).Delete(&rule).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"elk-stack-user/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OAuthRepository interface {
+	CreateClient(ctx context.Context, client *model.OAuthClient) error
+	// GetActiveClient iptal edilmemiş client'ı döner
+	GetActiveClient(ctx context.Context, clientID string) (*model.OAuthClient, error)
+	ListClients(ctx context.Context) ([]*model.OAuthClient, error)
+	// RevokeClient client'ı ve verdiği tüm access token'ları iptal eder
+	RevokeClient(ctx context.Context, id uint) error
+
+	CreateAuthorizationCode(ctx context.Context, code *model.OAuthAuthorizationCode) error
+	// ConsumeAuthorizationCode geçerli bir kodu atomik olarak kullanılmış işaretler,
+	// kod yoksa, süresi dolmuşsa veya daha önce kullanılmışsa gorm.ErrRecordNotFound döner
+	ConsumeAuthorizationCode(ctx context.Context, codeHash string) (*model.OAuthAuthorizationCode, error)
+
+	CreateAccessToken(ctx context.Context, token *model.OAuthAccessToken) error
+	GetActiveAccessToken(ctx context.Context, tokenHash string) (*model.OAuthAccessToken, error)
+	// RevokeAccessToken sadece clientID'ye ait token'ı iptal eder
+	RevokeAccessToken(ctx context.Context, tokenHash, clientID string) error
+}
+
+type oauthRepository struct {
+	db *gorm.DB
+}
+
+func NewOAuthRepository(db *gorm.DB) OAuthRepository {
+	return &oauthRepository{db: db}
+}
+
+func (r *oauthRepository) CreateClient(ctx context.Context, client *model.OAuthClient) error {
+	return r.db.WithContext(ctx).Create(client).Error
+}
+
+func (r *oauthRepository) GetActiveClient(ctx context.Context, clientID string) (*model.OAuthClient, error) {
+	var client model.OAuthClient
+	err := r.db.WithContext(ctx).
+		Where("client_id = ? AND revoked_at IS NULL", clientID).
+		First(&client).Error
+	if err != nil {
+		return nil, err
+	}
+	return &client, nil
+}
+
+func (r *oauthRepository) ListClients(ctx context.Context) ([]*model.OAuthClient, error) {
+	var clients []*model.OAuthClient
+	err := r.db.WithContext(ctx).Order("id ASC").Find(&clients).Error
+	return clients, err
+}
+
+func (r *oauthRepository) RevokeClient(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var client model.OAuthClient
+		if err := tx.Where("id = ? AND revoked_at IS NULL", id).First(&client).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		if err := tx.Model(&client).Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.OAuthAccessToken{}).
+			Where("client_id = ? AND revoked_at IS NULL", client.ClientID).
+			Update("revoked_at", now).Error
+	})
+}
+
+func (r *oauthRepository) CreateAuthorizationCode(ctx context.Context, code *model.OAuthAuthorizationCode) error {
+	return r.db.WithContext(ctx).Create(code).Error
+}
+
+func (r *oauthRepository) ConsumeAuthorizationCode(ctx context.Context, codeHash string) (*model.OAuthAuthorizationCode, error) {
+	var code model.OAuthAuthorizationCode
+	now := time.Now()
+	result := r.db.WithContext(ctx).Model(&code).
+		Clauses(clause.Returning{}).
+		Where("code_hash = ? AND used_at IS NULL AND expires_at > ?", codeHash, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &code, nil
+}
+
+func (r *oauthRepository) CreateAccessToken(ctx context.Context, token *model.OAuthAccessToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+func (r *oauthRepository) GetActiveAccessToken(ctx context.Context, tokenHash string) (*model.OAuthAccessToken, error) {
+	var token model.OAuthAccessToken
+	err := r.db.WithContext(ctx).
+		Where("token_hash = ? AND revoked_at IS NULL AND expires_at > ?", tokenHash, time.Now()).
+		First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *oauthRepository) RevokeAccessToken(ctx context.Context, tokenHash, clientID string) error {
+	return r.db.WithContext(ctx).Model(&model.OAuthAccessToken{}).
+		Where("token_hash = ? AND client_id = ? AND revoked_at IS NULL", tokenHash, clientID).
+		Update("revoked_at", time.Now()).Error
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"elk-stack-user/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TokenRepository interface {
+	Create(ctx context.Context, token *model.UserToken) error
+	// Consume geçerli bir token'ı atomik olarak kullanılmış işaretler ve döner
+	Consume(ctx context.Context, purpose, tokenHash string) (*model.UserToken, error)
+	InvalidateForUser(ctx context.Context, userID uint, purpose string) error
+	GetLatestForUser(ctx context.Context, userID uint, purpose string) (*model.UserToken, error)
+	// GetValid token'ı kullanılmış işaretlemeden döner
+	GetValid(ctx context.Context, purpose, tokenHash string) (*model.UserToken, error)
+}
+
+type tokenRepository struct {
+	db *gorm.DB
+}
+
+func NewTokenRepository(db *gorm.DB) TokenRepository {
+	return &tokenRepository{db: db}
+}
+
+func (r *tokenRepository) Create(ctx context.Context, token *model.UserToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+func (r *tokenRepository) Consume(ctx context.Context, purpose, tokenHash string) (*model.UserToken, error) {
+	var token model.UserToken
+	now := time.Now()
+	result := r.db.WithContext(ctx).Model(&token).
+		Clauses(clause.Returning{}).
+		Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", tokenHash, purpose, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &token, nil
+}
+
+func (r *tokenRepository) InvalidateForUser(ctx context.Context, userID uint, purpose string) error {
+	return r.db.WithContext(ctx).Model(&model.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", time.Now()).Error
+}
+
+func (r *tokenRepository) GetLatestForUser(ctx context.Context, userID uint, purpose string) (*model.UserToken, error) {
+	var token model.UserToken
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND purpose = ?", userID, purpose).
+		Order("created_at DESC").
+		First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *tokenRepository) GetValid(ctx context.Context, purpose, tokenHash string) (*model.UserToken, error) {
+	var token model.UserToken
+	err := r.db.WithContext(ctx).
+		Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", tokenHash, purpose, time.Now()).
+		First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
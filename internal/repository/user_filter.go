@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"strings"
+
+	"elk-stack-user/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// applyUserFilter filtreleri parametreli WHERE koşulları olarak ekler,
+// kullanıcı girdisi hiçbir zaman SQL metnine eklenmez
+func applyUserFilter(db *gorm.DB, filter *model.UserFilter) *gorm.DB {
+	if filter == nil {
+		return db
+	}
+
+	if filter.IsActive != nil {
+		db = db.Where("is_active = ?", *filter.IsActive)
+	}
+	if filter.MinAge != nil {
+		db = db.Where("age >= ?", *filter.MinAge)
+	}
+	if filter.MaxAge != nil {
+		db = db.Where("age <= ?", *filter.MaxAge)
+	}
+	if filter.CreatedAfter != nil {
+		db = db.Where("created_at >= ?", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		db = db.Where("created_at < ?", *filter.CreatedBefore)
+	}
+	if filter.UsernamePrefix != "" {
+		db = db.Where("lower(username) LIKE lower(?) ESCAPE '\\'", escapeLike(filter.UsernamePrefix)+"%")
+	}
+	if filter.EmailPrefix != "" {
+		db = db.Where("lower(email) LIKE lower(?) ESCAPE '\\'", escapeLike(filter.EmailPrefix)+"%")
+	}
+	for _, term := range strings.Fields(filter.Query) {
+		pattern := "%" + escapeLike(term) + "%"
+		db = db.Where("(first_name ILIKE ? ESCAPE '\\' OR last_name ILIKE ? ESCAPE '\\')", pattern, pattern)
+	}
+	return db
+}
+
+// applyUserSort UserSortKeys sırasına göre sıralar, backward true ise yönler ters çevrilir
+func applyUserSort(db *gorm.DB, sort []model.SortField, backward bool) *gorm.DB {
+	keys := model.UserSortKeys(sort)
+	columns := make([]clause.OrderByColumn, 0, len(keys))
+	for _, key := range keys {
+		columns = append(columns, clause.OrderByColumn{
+			Column: clause.Column{Name: model.UserSortColumns[key.Field]},
+			Desc:   key.Desc != backward,
+		})
+	}
+	return db.Clauses(clause.OrderBy{Columns: columns})
+}
+
+// applyUserCursor cursor'daki satırdan sonra (backward ise önce) gelen satırları seçer.
+// Karışık yönlü sıralamada row comparison kullanılamadığı için koşul
+// (a > ?) OR (a = ? AND b < ?) OR ... şeklinde açılır.
+func applyUserCursor(db *gorm.DB, sort []model.SortField, cursor *model.UserCursor) *gorm.DB {
+	keys := model.UserSortKeys(sort)
+	if len(cursor.Values) != len(keys) {
+		return db.Where("1 = 0")
+	}
+
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	for i, key := range keys {
+		var parts []string
+		for j := 0; j < i; j++ {
+			parts = append(parts, model.UserSortColumns[keys[j].Field]+" = ?")
+			args = append(args, cursor.Values[j])
+		}
+		op := ">"
+		if key.Desc != cursor.Backward {
+			op = "<"
+		}
+		parts = append(parts, model.UserSortColumns[key.Field]+" "+op+" ?")
+		args = append(args, cursor.Values[i])
+		conditions = append(conditions, "("+strings.Join(parts, " AND ")+")")
+	}
+	return db.Where("("+strings.Join(conditions, " OR ")+")", args...)
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"elk-stack-user/internal/model"
 	"time"
 )
@@ -12,17 +13,47 @@ type UserRepository interface {
 	GetByID(ctx context.Context, id uint) (*model.User, error)
 	GetByEmail(ctx context.Context, email string) (*model.User, error)
 	GetByUsername(ctx context.Context, username string) (*model.User, error)
-	GetAll(ctx context.Context, limit, offset int) ([]*model.User, error)
+	// List filtreye uyan kullanıcıları keyset pagination ile döner, cursor nil ise ilk sayfa
+	List(ctx context.Context, filter *model.UserFilter, cursor *model.UserCursor, limit int) ([]*model.User, error)
+	// Update düzenlenebilir alanları sadece user.Version hâlâ güncelse yazar, değilse ErrStaleVersion döner
 	Update(ctx context.Context, user *model.User) error
-	Delete(ctx context.Context, id uint) error
-	Count(ctx context.Context) (int64, error)
+	UpdatePassword(ctx context.Context, id uint, passwordHash string) error
+	MarkEmailVerified(ctx context.Context, id uint, verifiedAt time.Time) error
+	UpdateTOTP(ctx context.Context, id uint, secret string, enabledAt *time.Time) error
+	// AdvanceTOTPStep son kullanılan TOTP adımını ileri alır, adım daha önce kullanıldıysa false döner
+	AdvanceTOTPStep(ctx context.Context, id uint, step int64) (bool, error)
+	// Delete version 0 değilse sadece o versiyondaki kullanıcıyı siler, değişmişse ErrStaleVersion döner
+	Delete(ctx context.Context, id uint, version uint) error
+	Count(ctx context.Context, filter *model.UserFilter) (int64, error)
+	// Search full-text (prefix) ve trigram benzerliği ile eşleşen kullanıcıları skora göre döner
+	Search(ctx context.Context, query string, limit int) ([]*model.UserSearchResult, error)
+	// EstimateCount pg_class.reltuples'tan tablonun tahmini satır sayısı, tablo hiç analiz edilmediyse negatif döner
+	EstimateCount(ctx context.Context) (int64, error)
 	// Login methods
 	GetUserForLogin(ctx context.Context, usernameOrEmail string) (*model.User, error)
+	// SamplePasswordHash seed'e göre seçilen mevcut bir kullanıcının şifre hash'ini döner, kullanıcı yoksa ""
+	SamplePasswordHash(ctx context.Context, seed uint32) (string, error)
 	RecordLoginAttempt(ctx context.Context, attempt *model.LoginAttempt) error
-	GetFailedAttempts(ctx context.Context, username, ipAddress string, since time.Time) ([]*model.LoginAttempt, error)
+	// CountFailedAttempts banType'ın anahtarına (username, ip veya ikisi) göre başarısız denemeleri sayar
+	CountFailedAttempts(ctx context.Context, banType, username, ipAddress string, since time.Time) (int64, error)
 	RecordBan(ctx context.Context, ban *model.BanRecord) error
+	// CountBans aynı tip ve anahtar için since'ten sonra uygulanmış ban sayısı
+	CountBans(ctx context.Context, banType, username, ipAddress string, since time.Time) (int64, error)
 	IsBanned(ctx context.Context, username, ipAddress string) (*model.BanRecord, error)
-	RemoveExpiredBans(ctx context.Context) error
+	// ExtendBan aktif bir banın bitişini ileri alır, daha erken bir zamana çekmez
+	ExtendBan(ctx context.Context, id uint, expiresAt time.Time) error
+	// RemoveExpiredBans expiredBefore'dan önce süresi dolmuş ban kayıtlarını siler
+	RemoveExpiredBans(ctx context.Context, expiredBefore time.Time) (int64, error)
+	DeleteLoginAttemptsBefore(ctx context.Context, before time.Time) (int64, error)
+	// Soft-delete edilmiş kullanıcılar
+	ListDeleted(ctx context.Context, limit, offset int) ([]*model.User, int64, error)
+	GetDeletedByID(ctx context.Context, id uint) (*model.User, error)
+	// Restore soft-delete'i geri alır, kullanıcı silinmiş değilse gorm.ErrRecordNotFound döner
+	Restore(ctx context.Context, id uint) error
+	// Purge silinmiş kullanıcıları session, token ve kodlarıyla birlikte kalıcı olarak siler
+	Purge(ctx context.Context, ids ...uint) (int64, error)
+	// PurgeDeletedBefore before'dan önce silinmiş kullanıcıları batch'ler halinde kalıcı olarak siler
+	PurgeDeletedBefore(ctx context.Context, before time.Time, batchSize int) (int64, error)
 }
 
 type userRepository struct {
@@ -33,91 +64,227 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 	return &userRepository{db: db}
 }
 
+// errUserNotFound kullanıcı sorgularının NotFound mesajı
+const errUserNotFound = "user not found"
+
 func (r *userRepository) Create(ctx context.Context, user *model.User) error {
-	return r.db.WithContext(ctx).Create(user).Error
+	return translateError(r.db.WithContext(ctx).Create(user).Error, errUserNotFound)
 }
 
 func (r *userRepository) GetByID(ctx context.Context, id uint) (*model.User, error) {
 	var user model.User
 	err := r.db.WithContext(ctx).First(&user, id).Error
 	if err != nil {
-		return nil, err
+		return nil, translateError(err, errUserNotFound)
 	}
 	return &user, nil
 }
 
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
 	var user model.User
-	err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error
+	err := r.db.WithContext(ctx).Where("lower(email) = lower(?)", email).First(&user).Error
 	if err != nil {
-		return nil, err
+		return nil, translateError(err, errUserNotFound)
 	}
 	return &user, nil
 }
 
 func (r *userRepository) GetByUsername(ctx context.Context, username string) (*model.User, error) {
 	var user model.User
-	err := r.db.WithContext(ctx).Where("username = ?", username).First(&user).Error
+	err := r.db.WithContext(ctx).Where("lower(username) = lower(?)", username).First(&user).Error
 	if err != nil {
-		return nil, err
+		return nil, translateError(err, errUserNotFound)
 	}
 	return &user, nil
 }
 
-func (r *userRepository) GetAll(ctx context.Context, limit, offset int) ([]*model.User, error) {
+func (r *userRepository) List(ctx context.Context, filter *model.UserFilter, cursor *model.UserCursor, limit int) ([]*model.User, error) {
 	var users []*model.User
-	err := r.db.WithContext(ctx).Limit(limit).Offset(offset).Find(&users).Error
+	backward := cursor != nil && cursor.Backward
+	query := applyUserFilter(r.db.WithContext(ctx).Model(&model.User{}), filter)
+	if cursor != nil {
+		query = applyUserCursor(query, filter.Sort, cursor)
+	}
+	err := applyUserSort(query, filter.Sort, backward).Limit(limit).Find(&users).Error
 	if err != nil {
 		return nil, err
 	}
+	if backward {
+		// Önceki sayfa ters sırayla okunur, gösterim sırasına çevrilir
+		for i, j := 0, len(users)-1; i < j; i, j = i+1, j-1 {
+			users[i], users[j] = users[j], users[i]
+		}
+	}
 	return users, nil
 }
 
+// nextVersion users satırına yapılan her yazmada version'ı artırır, böylece
+// ETag'ler updated_at dahil temsilin her değişiminde değişir
+var nextVersion = gorm.Expr("version + 1")
+
+// Save yerine WHERE version = ? ile yazılır: iki eşzamanlı read-modify-write'tan
+// ikincisi birincinin değişikliklerini ezmek yerine ErrStaleVersion alır
 func (r *userRepository) Update(ctx context.Context, user *model.User) error {
-	return r.db.WithContext(ctx).Save(user).Error
+	now := time.Now()
+	result := r.db.WithContext(ctx).Model(&model.User{}).
+		Where("id = ? AND version = ?", user.ID, user.Version).
+		Updates(map[string]interface{}{
+			"username":          user.Username,
+			"email":             user.Email,
+			"first_name":        user.FirstName,
+			"last_name":         user.LastName,
+			"age":               user.Age,
+			"is_active":         user.IsActive,
+			"email_verified_at": user.EmailVerifiedAt,
+			"updated_at":        now,
+			"version":           nextVersion,
+		})
+	if result.Error != nil {
+		return translateError(result.Error, errUserNotFound)
+	}
+	if result.RowsAffected == 0 {
+		return ErrStaleVersion
+	}
+	user.UpdatedAt = now
+	user.Version++
+	return nil
+}
+
+func (r *userRepository) UpdatePassword(ctx context.Context, id uint, passwordHash string) error {
+	return r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"password": passwordHash, "version": nextVersion}).Error
+}
+
+func (r *userRepository) MarkEmailVerified(ctx context.Context, id uint, verifiedAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&model.User{}).
+		Where("id = ? AND email_verified_at IS NULL", id).
+		Updates(map[string]interface{}{"email_verified_at": verifiedAt, "version": nextVersion}).Error
+}
+
+func (r *userRepository) UpdateTOTP(ctx context.Context, id uint, secret string, enabledAt *time.Time) error {
+	return r.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"totp_secret":     secret,
+			"totp_enabled_at": enabledAt,
+			"totp_last_step":  0,
+			"version":         nextVersion,
+		}).Error
+}
+
+func (r *userRepository) AdvanceTOTPStep(ctx context.Context, id uint, step int64) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&model.User{}).
+		Where("id = ? AND totp_last_step < ?", id, step).
+		Updates(map[string]interface{}{"totp_last_step": step, "version": nextVersion})
+	return result.RowsAffected > 0, result.Error
 }
 
-func (r *userRepository) Delete(ctx context.Context, id uint) error {
-	return r.db.WithContext(ctx).Delete(&model.User{}, id).Error
+func (r *userRepository) Delete(ctx context.Context, id uint, version uint) error {
+	query := r.db.WithContext(ctx)
+	if version != 0 {
+		query = query.Where("version = ?", version)
+	}
+	result := query.Delete(&model.User{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		if version != 0 {
+			// Satır hâlâ varsa versiyon değişmiştir, yoksa silinmiştir
+			if _, err := r.GetByID(ctx, id); err == nil {
+				return ErrStaleVersion
+			}
+		}
+		return translateError(gorm.ErrRecordNotFound, errUserNotFound)
+	}
+	return nil
 }
 
-func (r *userRepository) Count(ctx context.Context) (int64, error) {
+func (r *userRepository) Count(ctx context.Context, filter *model.UserFilter) (int64, error) {
 	var count int64
-	err := r.db.WithContext(ctx).Model(&model.User{}).Count(&count).Error
+	err := applyUserFilter(r.db.WithContext(ctx).Model(&model.User{}), filter).Count(&count).Error
 	return count, err
 }
 
+func (r *userRepository) EstimateCount(ctx context.Context) (int64, error) {
+	var estimate float64
+	err := r.db.WithContext(ctx).
+		Raw("SELECT reltuples FROM pg_class WHERE oid = to_regclass('users')").
+		Scan(&estimate).Error
+	return int64(estimate), err
+}
+
 func (r *userRepository) GetUserForLogin(ctx context.Context, usernameOrEmail string) (*model.User, error) {
 	var user model.User
-	err := r.db.WithContext(ctx).Where("username = ? OR email = ?", usernameOrEmail, usernameOrEmail).First(&user).Error
+	err := r.db.WithContext(ctx).
+		Where("lower(username) = lower(?) OR lower(email) = lower(?)", usernameOrEmail, usernameOrEmail).
+		First(&user).Error
 	if err != nil {
-		return nil, err
+		return nil, translateError(err, errUserNotFound)
 	}
 	return &user, nil
 }
 
+// SamplePasswordHash id aralığında seed'in düştüğü noktadan sonraki ilk kullanıcının hash'ini
+// PK index'i üzerinden okur. Aynı seed hep aynı kullanıcıya denk gelir; seçilen hash'ler
+// tablodaki algoritma ve parametre dağılımını izler.
+func (r *userRepository) SamplePasswordHash(ctx context.Context, seed uint32) (string, error) {
+	// Tek sorgu: bilinmeyen kullanıcı yolu gerçek login'e göre fazladan round-trip harcamasın
+	var hashes []string
+	err := r.db.WithContext(ctx).Model(&model.User{}).
+		Where("id >= (SELECT COALESCE(MAX(id), 0) FROM users) * ?::bigint / 4294967296", seed).
+		Order("id").Limit(1).
+		Pluck("password", &hashes).Error
+	if err != nil || len(hashes) == 0 {
+		return "", err
+	}
+	return hashes[0], nil
+}
+
 func (r *userRepository) RecordLoginAttempt(ctx context.Context, attempt *model.LoginAttempt) error {
 	return r.db.WithContext(ctx).Create(attempt).Error
 }
 
-func (r *userRepository) GetFailedAttempts(ctx context.Context, username, ipAddress string, since time.Time) ([]*model.LoginAttempt, error) {
-	var attempts []*model.LoginAttempt
-	err := r.db.WithContext(ctx).
-		Where("(username = ? OR ip_address = ?) AND success = ? AND timestamp > ?", 
-			username, ipAddress, false, since).
-		Find(&attempts).Error
-	return attempts, err
+func (r *userRepository) CountFailedAttempts(ctx context.Context, banType, username, ipAddress string, since time.Time) (int64, error) {
+	var count int64
+	err := banKeyScope(r.db.WithContext(ctx).Model(&model.LoginAttempt{}), banType, username, ipAddress).
+		Where("success = ? AND blocked = ? AND timestamp > ?", false, false, since).
+		Count(&count).Error
+	return count, err
 }
 
 func (r *userRepository) RecordBan(ctx context.Context, ban *model.BanRecord) error {
 	return r.db.WithContext(ctx).Create(ban).Error
 }
 
+func (r *userRepository) CountBans(ctx context.Context, banType, username, ipAddress string, since time.Time) (int64, error) {
+	var count int64
+	err := banKeyScope(r.db.WithContext(ctx).Model(&model.BanRecord{}), banType, username, ipAddress).
+		Where("type = ? AND banned_at > ? AND lifted_at IS NULL", banType, since).
+		Count(&count).Error
+	return count, err
+}
+
+// banKeyScope ban tipinin anahtar kolonlarına göre filtre ekler
+func banKeyScope(db *gorm.DB, banType, username, ipAddress string) *gorm.DB {
+	switch banType {
+	case model.BanTypeUsername:
+		return db.Where("username = ?", username)
+	case model.BanTypeIP:
+		return db.Where("ip_address = ?", ipAddress)
+	default:
+		return db.Where("username = ? AND ip_address = ?", username, ipAddress)
+	}
+}
+
 func (r *userRepository) IsBanned(ctx context.Context, username, ipAddress string) (*model.BanRecord, error) {
 	var ban model.BanRecord
 	err := r.db.WithContext(ctx).
-		Where("(username = ? OR ip_address = ?) AND expires_at > ?", 
-			username, ipAddress, time.Now()).
+		Where("expires_at > ? AND lifted_at IS NULL", time.Now()).
+		Where("(type = ? AND username = ?) OR (type = ? AND ip_address = ?) OR (type = ? AND username = ? AND ip_address = ?)",
+			model.BanTypeUsername, username,
+			model.BanTypeIP, ipAddress,
+			model.BanTypeUsernameIP, username, ipAddress).
+		Order("expires_at DESC").
 		First(&ban).Error
 	if err != nil {
 		return nil, err
@@ -125,6 +292,108 @@ func (r *userRepository) IsBanned(ctx context.Context, username, ipAddress strin
 	return &ban, nil
 }
 
-func (r *userRepository) RemoveExpiredBans(ctx context.Context) error {
-	return r.db.WithContext(ctx).Where("expires_at <= ?", time.Now()).Delete(&model.BanRecord{}).Error
+func (r *userRepository) ExtendBan(ctx context.Context, id uint, expiresAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&model.BanRecord{}).
+		Where("id = ? AND expires_at < ? AND lifted_at IS NULL", id, expiresAt).
+		Update("expires_at", expiresAt).Error
+}
+
+func (r *userRepository) RemoveExpiredBans(ctx context.Context, expiredBefore time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at <= ?", expiredBefore).Delete(&model.BanRecord{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *userRepository) DeleteLoginAttemptsBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("timestamp < ?", before).Delete(&model.LoginAttempt{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *userRepository) ListDeleted(ctx context.Context, limit, offset int) ([]*model.User, int64, error) {
+	query := r.db.WithContext(ctx).Unscoped().Model(&model.User{}).Where("deleted_at IS NOT NULL")
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []*model.User
+	err := query.Order("deleted_at DESC, id").Limit(limit).Offset(offset).Find(&users).Error
+	return users, total, err
+}
+
+func (r *userRepository) GetDeletedByID(ctx context.Context, id uint) (*model.User, error) {
+	var user model.User
+	err := r.db.WithContext(ctx).Unscoped().Where("deleted_at IS NOT NULL").First(&user, id).Error
+	if err != nil {
+		return nil, translateError(err, errUserNotFound)
+	}
+	return &user, nil
+}
+
+func (r *userRepository) Restore(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Unscoped().Model(&model.User{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Updates(map[string]interface{}{"deleted_at": nil, "version": nextVersion})
+	if result.Error != nil {
+		return translateError(result.Error, errUserNotFound)
+	}
+	if result.RowsAffected == 0 {
+		return translateError(gorm.ErrRecordNotFound, errUserNotFound)
+	}
+	return nil
+}
+
+func (r *userRepository) Purge(ctx context.Context, ids ...uint) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	var purged int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Sadece hâlâ silinmiş olan satırlar, arada restore edilenler atlanır
+		var deleted []uint
+		if err := tx.Unscoped().Model(&model.User{}).
+			Where("id IN ? AND deleted_at IS NOT NULL", ids).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Pluck("id", &deleted).Error; err != nil {
+			return err
+		}
+		if len(deleted) == 0 {
+			return nil
+		}
+
+		for _, related := range []interface{}{
+			&model.Session{}, &model.UserToken{}, &model.RecoveryCode{},
+			&model.OAuthAuthorizationCode{}, &model.OAuthAccessToken{},
+		} {
+			if err := tx.Where("user_id IN ?", deleted).Delete(related).Error; err != nil {
+				return err
+			}
+		}
+
+		result := tx.Unscoped().Where("id IN ?", deleted).Delete(&model.User{})
+		purged = result.RowsAffected
+		return result.Error
+	})
+	return purged, err
+}
+
+func (r *userRepository) PurgeDeletedBefore(ctx context.Context, before time.Time, batchSize int) (int64, error) {
+	var total int64
+	for {
+		var ids []uint
+		err := r.db.WithContext(ctx).Unscoped().Model(&model.User{}).
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+			Order("id").Limit(batchSize).
+			Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return total, err
+		}
+
+		purged, err := r.Purge(ctx, ids...)
+		total += purged
+		if err != nil || len(ids) < batchSize {
+			return total, err
+		}
+	}
 }
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"elk-stack-user/internal/model"
+)
+
+// Highlight işaretleri; service katmanı metni escape ettikten sonra bunları <mark>'a çevirir
+const (
+	HighlightStart = "⟦"
+	HighlightStop  = "⟧"
+)
+
+// searchDocument ts_headline'ın işaretlediği, search_vector ile aynı alanlardan oluşan metin
+const searchDocument = "concat_ws(' ', username, first_name, last_name, split_part(email, '@', 1))"
+
+// userSearchRow Search sorgusunun satırı
+type userSearchRow struct {
+	model.User
+	Rank       float64
+	Similarity float64
+	Highlight  string
+}
+
+// prefixTSQuery sorguyu "kelime:* & kelime:*" formatında bir tsquery'ye çevirir.
+// Harf ve rakam dışındaki karakterler ayırıcı sayılır, böylece tsquery sözdizimi enjekte edilemez.
+func prefixTSQuery(query string) string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+	return strings.Join(words, " & ")
+}
+
+func (r *userRepository) Search(ctx context.Context, query string, limit int) ([]*model.UserSearchResult, error) {
+	tsquery := prefixTSQuery(query)
+	if tsquery == "" {
+		return nil, nil
+	}
+
+	// ORDER BY alias'ları toplayamadığı için skor ifadesi ayrıca seçilir
+	rank := "ts_rank_cd(search_vector, to_tsquery('simple', @tsquery))"
+	similarity := "GREATEST(similarity(username, @query), similarity(first_name, @query), similarity(last_name, @query), similarity(split_part(email, '@', 1), @query))"
+
+	var rows []userSearchRow
+	err := r.db.WithContext(ctx).Model(&model.User{}).
+		Select("users.*, "+
+			rank+" AS rank, "+
+			similarity+" AS similarity, "+
+			rank+" + "+similarity+" AS score, "+
+			"ts_headline('simple', "+searchDocument+", to_tsquery('simple', @tsquery), @options) AS highlight",
+			map[string]interface{}{
+				"tsquery": tsquery,
+				"query":   query,
+				"options": `StartSel="` + HighlightStart + `", StopSel="` + HighlightStop + `", HighlightAll=true`,
+			}).
+		Where("search_vector @@ to_tsquery('simple', @tsquery) OR username % @query OR first_name % @query OR last_name % @query OR split_part(email, '@', 1) % @query",
+			map[string]interface{}{"tsquery": tsquery, "query": query}).
+		Order("score DESC, id").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*model.UserSearchResult, len(rows))
+	for i := range rows {
+		results[i] = &model.UserSearchResult{
+			User:       &rows[i].User,
+			Rank:       rows[i].Rank,
+			Similarity: rows[i].Similarity,
+			Highlight:  rows[i].Highlight,
+		}
+	}
+	return results, nil
+}
@@ -2,20 +2,47 @@ package router
 
 import (
 	"elk-stack-user/internal/handler"
+	"elk-stack-user/internal/ipacl"
+	"elk-stack-user/internal/logger"
+	"elk-stack-user/internal/mailer"
+	"elk-stack-user/internal/middleware"
+	"elk-stack-user/internal/model"
 	"elk-stack-user/internal/service"
 	"elk-stack-user/internal/repository"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
-func SetupRouter(db *gorm.DB) *gin.Engine {
+func SetupRouter(db *gorm.DB, mail mailer.Mailer, config *service.Config, acl *ipacl.ACL) *gin.Engine {
 	// Repository ve service'leri oluştur
 	userRepo := repository.NewUserRepository(db)
-	userService := service.NewUserService(userRepo)
+	sessionRepo := repository.NewSessionRepository(db)
+	tokenRepo := repository.NewTokenRepository(db)
+	recoveryCodeRepo := repository.NewRecoveryCodeRepository(db)
+	userService := service.NewUserService(userRepo, sessionRepo, tokenRepo, recoveryCodeRepo, mail, config)
 	userHandler := handler.NewUserHandler(userService)
+	banService := service.NewBanService(repository.NewBanRepository(db))
+	banHandler := handler.NewBanHandler(banService)
+	ipRuleService := service.NewIPRuleService(repository.NewIPRuleRepository(db), acl)
+	ipRuleHandler := handler.NewIPRuleHandler(ipRuleService)
+	apiKeyService := service.NewAPIKeyService(repository.NewAPIKeyRepository(db))
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
+	oauthService := service.NewOAuthService(repository.NewOAuthRepository(db), userService, config)
+	oauthHandler := handler.NewOAuthHandler(oauthService)
+
+	// Validation hataları client'ın gördüğü json alan adlarıyla raporlanır
+	handler.UseJSONFieldNames()
 
 	// Gin router'ı oluştur
-	router := gin.Default()
+	router := gin.New()
+	router.Use(logger.RequestIDMiddleware(), logger.LoggingMiddleware(), logger.RecoveryLogger(), middleware.ErrorHandler())
+
+	// X-Forwarded-For sadece güvenilen proxy'lerden kabul edilir, aksi halde
+	// c.ClientIP() (ban takibi ve IP kuralları) istemci tarafından taklit edilebilir
+	if err := router.SetTrustedProxies(acl.TrustedProxies()); err != nil {
+		panic("invalid trusted proxies: " + err.Error())
+	}
+	router.Use(middleware.DenyIPs(acl))
 
 	// Health check endpoint
 	router.GET("/health", func(c *gin.Context) {
@@ -27,14 +54,68 @@ func SetupRouter(db *gorm.DB) *gin.Engine {
 
 	// Login endpoint
 	router.POST("/login", userHandler.Login)
+	router.POST("/login/mfa", userHandler.LoginMFA)
+
+	// Password reset endpoints
+	router.POST("/password/forgot", userHandler.ForgotPassword)
+	router.GET("/password/reset", userHandler.ResetPasswordForm)
+	router.POST("/password/reset", userHandler.ResetPassword)
+
+	// Email verification endpoints
+	router.GET("/verify-email", userHandler.VerifyEmail)
+	router.POST("/verify-email/resend", userHandler.ResendVerificationEmail)
+
+	// OAuth2 / OpenID Connect provider
+	router.GET("/.well-known/openid-configuration", oauthHandler.Discovery)
+	oauth := router.Group("/oauth")
+	oauth.GET("/authorize", oauthHandler.Authorize)
+	oauth.POST("/authorize", oauthHandler.AuthorizeSubmit)
+	oauth.POST("/token", oauthHandler.Token)
+	oauth.POST("/introspect", oauthHandler.Introspect)
+	oauth.POST("/revoke", oauthHandler.Revoke)
+	oauth.GET("/userinfo", oauthHandler.UserInfo)
+	oauth.GET("/jwks.json", oauthHandler.JWKS)
+
+	// Authenticated user routes
+	me := router.Group("/me", middleware.RequireAuth(userService))
+	me.POST("/password", userHandler.ChangePassword)
+	me.POST("/mfa/totp", userHandler.EnrollTOTP)
+	me.GET("/mfa/totp/qr.png", userHandler.TOTPQRCode)
+	me.POST("/mfa/totp/confirm", userHandler.ConfirmTOTP)
+	me.POST("/mfa/totp/disable", userHandler.DisableTOTP)
+
+	// Admin routes
+	admin := router.Group("/admin", middleware.AllowAdminIPs(acl), middleware.RequireAuth(userService), middleware.RequireAdmin())
+	admin.GET("/bans", banHandler.ListBans)
+	admin.POST("/bans", banHandler.CreateBan)
+	admin.GET("/bans/:id", banHandler.GetBan)
+	admin.DELETE("/bans/:id", banHandler.LiftBan)
+	admin.GET("/ip-rules", ipRuleHandler.ListIPRules)
+	admin.POST("/ip-rules", ipRuleHandler.CreateIPRule)
+	admin.DELETE("/ip-rules/:id", ipRuleHandler.DeleteIPRule)
+	admin.GET("/api-keys", apiKeyHandler.ListAPIKeys)
+	admin.POST("/api-keys", apiKeyHandler.CreateAPIKey)
+	admin.POST("/api-keys/:id/rotate", apiKeyHandler.RotateAPIKey)
+	admin.DELETE("/api-keys/:id", apiKeyHandler.RevokeAPIKey)
+	admin.GET("/users/deleted", userHandler.ListDeletedUsers)
+	admin.POST("/users/:id/restore", userHandler.RestoreUser)
+	admin.DELETE("/users/:id", userHandler.PurgeUser)
+	admin.GET("/oauth/clients", oauthHandler.ListClients)
+	admin.POST("/oauth/clients", oauthHandler.RegisterClient)
+	admin.DELETE("/oauth/clients/:id", oauthHandler.RevokeClient)
 
 	// User routes
+	// Kayıt herkese açık. Diğer işlemler session veya API key ister: API key'ler
+	// users:read/users:write scope'u, kullanıcılar admin rolü ya da kendi hesapları olmalı
 	router.POST("/users", userHandler.CreateUser)
-	router.GET("/users", userHandler.GetAllUsers)
-	router.GET("/users/email", userHandler.GetUserByEmail)
-	router.GET("/users/:id", userHandler.GetUserByID)
-	router.PUT("/users/:id", userHandler.UpdateUser)
-	router.DELETE("/users/:id", userHandler.DeleteUser)
+	users := router.Group("/users", middleware.Authenticate(userService, apiKeyService))
+	users.GET("", middleware.RequireScope(model.ScopeUsersRead), userHandler.GetAllUsers)
+	users.GET("/email", middleware.RequireScope(model.ScopeUsersRead), userHandler.GetUserByEmail)
+	users.GET("/search", middleware.RequireScope(model.ScopeUsersRead), userHandler.SearchUsers)
+	users.GET("/:id", middleware.RequireScope(model.ScopeUsersRead), userHandler.GetUserByID)
+	users.PUT("/:id", middleware.RequireScope(model.ScopeUsersWrite), userHandler.ReplaceUser)
+	users.PATCH("/:id", middleware.RequireScope(model.ScopeUsersWrite), userHandler.PatchUser)
+	users.DELETE("/:id", middleware.RequireScope(model.ScopeUsersWrite), userHandler.DeleteUser)
 
 	return router
 }
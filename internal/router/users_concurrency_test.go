@@ -0,0 +1,178 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"elk-stack-user/internal/database"
+	"elk-stack-user/internal/ipacl"
+	"elk-stack-user/internal/logger"
+	"elk-stack-user/internal/mailer"
+	"elk-stack-user/internal/middleware"
+	"elk-stack-user/internal/passhash"
+	"elk-stack-user/internal/service"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// testDatabase TEST_DATABASE_DSN ile verilen Postgres'e bağlanır ve şemayı migrate eder.
+// Unique index davranışı ancak gerçek veritabanında doğrulanabildiği için DSN yoksa test atlanır.
+func testDatabase(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN not set, skipping Postgres test")
+	}
+	if logger.Logger == nil {
+		logger.Logger = zap.NewNop()
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	if err := database.AutoMigrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	t.Cleanup(func() { database.Close(db) })
+	return db
+}
+
+type problem struct {
+	Status int `json:"status"`
+	Errors []struct {
+		Field string `json:"field"`
+		Rule  string `json:"rule"`
+	} `json:"errors"`
+}
+
+// TestConcurrentDuplicateSignups aynı kullanıcı adı veya e-postayla eşzamanlı POST /users
+// isteklerinden sadece birinin kabul edildiğini, diğerlerinin çakışan alanı gösteren 409
+// aldığını doğrular.
+func TestConcurrentDuplicateSignups(t *testing.T) {
+	db := testDatabase(t)
+
+	gin.SetMode(gin.TestMode)
+	config := service.NewConfig()
+	config.PasswordHasher = passhash.NewManager(passhash.NewBcryptHasher(4))
+	acl, err := ipacl.New(&ipacl.Config{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := SetupRouter(db, mailer.NewMemoryMailer(), config, acl)
+
+	const workers = 16
+	run := fmt.Sprintf("race%d", time.Now().UnixNano())
+	t.Cleanup(func() {
+		// Kayıtta gönderilen doğrulama e-postasının token'ı da kullanıcıyla birlikte silinir
+		db.Exec("DELETE FROM user_tokens WHERE user_id IN (SELECT id FROM users WHERE username LIKE ?)", run+"%")
+		db.Exec("DELETE FROM users WHERE username LIKE ?", run+"%")
+	})
+
+	tests := []struct {
+		name string
+		// body i. isteğin gövdesi
+		body func(i int) gin.H
+		// fields 409'daki errors[0].field için kabul edilen değerler
+		fields []string
+	}{
+		{
+			name: "identical",
+			body: func(i int) gin.H {
+				return gin.H{"username": run + "same", "email": run + "same@example.com"}
+			},
+			// İki index de ihlal edilir, Postgres hangisini önce kontrol ederse o raporlanır
+			fields: []string{"username", "email"},
+		},
+		{
+			name: "username",
+			body: func(i int) gin.H {
+				return gin.H{"username": run + "user", "email": fmt.Sprintf("%suser%d@example.com", run, i)}
+			},
+			fields: []string{"username"},
+		},
+		{
+			name: "email differing in case",
+			body: func(i int) gin.H {
+				email := run + "mail@example.com"
+				if i%2 == 1 {
+					email = strings.ToUpper(email)
+				}
+				return gin.H{"username": fmt.Sprintf("%smail%d", run, i), "email": email}
+			},
+			fields: []string{"email"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				start     = make(chan struct{})
+				wg        sync.WaitGroup
+				responses = make([]*httptest.ResponseRecorder, workers)
+			)
+			for i := 0; i < workers; i++ {
+				body := tt.body(i)
+				body["password"] = "correct horse battery staple"
+				payload, _ := json.Marshal(body)
+
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					req := httptest.NewRequest(http.MethodPost, "/users", bytes.NewReader(payload))
+					req.Header.Set("Content-Type", "application/json")
+					w := httptest.NewRecorder()
+					<-start
+					router.ServeHTTP(w, req)
+					responses[i] = w
+				}(i)
+			}
+			close(start)
+			wg.Wait()
+
+			created := 0
+			for i, w := range responses {
+				switch w.Code {
+				case http.StatusCreated:
+					created++
+				case http.StatusConflict:
+					if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, middleware.ProblemContentType) {
+						t.Errorf("request %d: Content-Type %q, want %s", i, ct, middleware.ProblemContentType)
+					}
+					var p problem
+					if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+						t.Fatalf("request %d: decode problem: %v", i, err)
+					}
+					if len(p.Errors) != 1 || p.Errors[0].Rule != "unique" || !contains(tt.fields, p.Errors[0].Field) {
+						t.Errorf("request %d: conflict errors %+v, want one unique violation on %v", i, p.Errors, tt.fields)
+					}
+				default:
+					t.Errorf("request %d: status %d, body %s", i, w.Code, w.Body.String())
+				}
+			}
+			if created != 1 {
+				t.Errorf("got %d created users, want exactly 1", created)
+			}
+		})
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package scheduler
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"time"
+
+	"elk-stack-user/internal/logger"
+	"elk-stack-user/internal/repository"
+)
+
+type Config struct {
+	Enabled bool
+	Jitter  time.Duration
+	Timeout time.Duration
+
+	BanPurgeSchedule string
+	// BanRetention süresi dolmuş ban'ların silinmeden önce tutulacağı süre.
+	// Ban escalation geçmişi kaybolmasın diye BAN_ESCALATION_WINDOW'dan kısa olmamalı.
+	BanRetention time.Duration
+
+	LoginAttemptPruneSchedule string
+	LoginAttemptRetention     time.Duration
+
+	DeletedUserPurgeSchedule string
+	// DeletedUserRetention soft-delete edilmiş kullanıcıların kalıcı silinmeden önce tutulacağı süre, 0 job'ı kapatır
+	DeletedUserRetention time.Duration
+}
+
+func NewConfig() *Config {
+	return &Config{
+		Enabled: getEnvBool("SCHEDULER_ENABLED", true),
+		Jitter:  getEnvDuration("SCHEDULER_JITTER", 30*time.Second),
+		Timeout: getEnvDuration("SCHEDULER_JOB_TIMEOUT", 5*time.Minute),
+
+		BanPurgeSchedule: getEnv("BAN_PURGE_SCHEDULE", "*/10 * * * *"),
+		BanRetention:     getEnvDuration("BAN_RETENTION", 24*time.Hour),
+
+		LoginAttemptPruneSchedule: getEnv("LOGIN_ATTEMPT_PRUNE_SCHEDULE", "0 3 * * *"),
+		LoginAttemptRetention:     getEnvDuration("LOGIN_ATTEMPT_RETENTION", 30*24*time.Hour),
+
+		DeletedUserPurgeSchedule: getEnv("DELETED_USER_PURGE_SCHEDULE", "30 3 * * *"),
+		DeletedUserRetention:     getEnvDuration("DELETED_USER_RETENTION", 30*24*time.Hour),
+	}
+}
+
+// RegisterMaintenanceJobs yerleşik bakım job'larını scheduler'a ekler
+func RegisterMaintenanceJobs(s *Scheduler, config *Config, userRepo repository.UserRepository) error {
+	if err := s.Add("purge_expired_bans", config.BanPurgeSchedule, config.Jitter, config.Timeout,
+		PurgeExpiredBans(userRepo, config.BanRetention)); err != nil {
+		return err
+	}
+	if err := s.Add("prune_login_attempts", config.LoginAttemptPruneSchedule, config.Jitter, config.Timeout,
+		PruneLoginAttempts(userRepo, config.LoginAttemptRetention)); err != nil {
+		return err
+	}
+	if config.DeletedUserRetention <= 0 {
+		return nil
+	}
+	return s.Add("purge_deleted_users", config.DeletedUserPurgeSchedule, config.Jitter, config.Timeout,
+		PurgeDeletedUsers(userRepo, config.DeletedUserRetention))
+}
+
+// PurgeExpiredBans süresi retention'dan daha önce dolmuş ban kayıtlarını siler
+func PurgeExpiredBans(userRepo repository.UserRepository, retention time.Duration) JobFunc {
+	return func(ctx context.Context) error {
+		removed, err := userRepo.RemoveExpiredBans(ctx, time.Now().Add(-retention))
+		if err != nil {
+			return err
+		}
+		logger.Logger.Info("Expired bans purged",
+			logger.Int64("removed", removed),
+			logger.Duration("retention", retention),
+		)
+		return nil
+	}
+}
+
+// PruneLoginAttempts retention'dan eski login denemelerini siler
+func PruneLoginAttempts(userRepo repository.UserRepository, retention time.Duration) JobFunc {
+	return func(ctx context.Context) error {
+		removed, err := userRepo.DeleteLoginAttemptsBefore(ctx, time.Now().Add(-retention))
+		if err != nil {
+			return err
+		}
+		logger.Logger.Info("Old login attempts pruned",
+			logger.Int64("removed", removed),
+			logger.Duration("retention", retention),
+		)
+		return nil
+	}
+}
+
+// purgeBatchSize kalıcı silmede tek transaction'daki en fazla kullanıcı
+const purgeBatchSize = 500
+
+// PurgeDeletedUsers retention'dan önce soft-delete edilmiş kullanıcıları kalıcı olarak siler
+func PurgeDeletedUsers(userRepo repository.UserRepository, retention time.Duration) JobFunc {
+	return func(ctx context.Context) error {
+		purged, err := userRepo.PurgeDeletedBefore(ctx, time.Now().Add(-retention), purgeBatchSize)
+		if err != nil {
+			return err
+		}
+		logger.Logger.Info("Deleted users purged",
+			logger.Int64("purged", purged),
+			logger.Duration("retention", retention),
+		)
+		return nil
+	}
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if d, err := time.ParseDuration(getEnv(key, "")); err == nil {
+		return d
+	}
+	return defaultValue
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if b, err := strconv.ParseBool(getEnv(key, "")); err == nil {
+		return b
+	}
+	return defaultValue
+}
@@ -0,0 +1,75 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"hash/fnv"
+	"time"
+)
+
+// Locker birden fazla instance çalışırken bir job'ın her zamanlanmış çalışmasının (tick)
+// cluster genelinde tek bir kez çalışmasını sağlar
+type Locker interface {
+	// TryLock tick'i bu instance adına alır. Lock başka instance'taysa veya tick zaten
+	// çalıştırılmışsa ok=false döner; alınırsa iş bitince unlock çağrılmalıdır.
+	// leaseUntil job'ın bir sonraki zamanlanmış çalışmasıdır, o ana kadar tick tekrar alınamaz.
+	TryLock(ctx context.Context, name string, tick, leaseUntil time.Time) (unlock func(), ok bool, err error)
+}
+
+// PostgresLocker session seviyesinde Postgres advisory lock kullanır.
+// Lock bağlantıya bağlı olduğu için job süresince pool'dan ayrı bir bağlantı tutulur.
+// Advisory lock sadece eşzamanlı çalışmayı engeller; jitter yüzünden farklı anlarda uyanan
+// instance'lar aynı tick'i tekrar çalıştırmasın diye lock altında scheduler_leases satırı da
+// kontrol edilip ilerletilir.
+type PostgresLocker struct {
+	db *sql.DB
+}
+
+func NewPostgresLocker(db *sql.DB) *PostgresLocker {
+	return &PostgresLocker{db: db}
+}
+
+func (l *PostgresLocker) TryLock(ctx context.Context, name string, tick, leaseUntil time.Time) (func(), bool, error) {
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	key := lockKey(name)
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&locked); err != nil {
+		conn.Close()
+		return nil, false, err
+	}
+	if !locked {
+		conn.Close()
+		return nil, false, nil
+	}
+
+	unlock := func() {
+		// Job context'i iptal edilmiş olabilir, unlock her durumda çalışmalı
+		conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", key)
+		conn.Close()
+	}
+
+	// Lease bu tick'e kadar dolmamışsa başka bir instance tick'i zaten çalıştırdı
+	result, err := conn.ExecContext(ctx, `INSERT INTO scheduler_leases (job, leased_until) VALUES ($1, $2)
+		ON CONFLICT (job) DO UPDATE SET leased_until = EXCLUDED.leased_until
+		WHERE scheduler_leases.leased_until <= $3`, name, leaseUntil, tick)
+	if err != nil {
+		unlock()
+		return nil, false, err
+	}
+	if claimed, err := result.RowsAffected(); err != nil || claimed == 0 {
+		unlock()
+		return nil, false, err
+	}
+	return unlock, true, nil
+}
+
+// lockKey job adından advisory lock için 64 bit anahtar üretir
+func lockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("elk-stack-user:scheduler:" + name))
+	return int64(h.Sum64())
+}
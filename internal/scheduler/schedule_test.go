@@ -0,0 +1,102 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleNext(t *testing.T) {
+	// 2024-01-01 bir Pazartesi
+	at := func(value string) time.Time {
+		tm, err := time.Parse("2006-01-02 15:04:05", value)
+		if err != nil {
+			panic(err)
+		}
+		return tm
+	}
+
+	tests := []struct {
+		name  string
+		spec  string
+		after string
+		// want boşsa Next sıfır zaman dönmeli
+		want string
+	}{
+		{"every minute skips seconds", "* * * * *", "2024-01-01 10:00:30", "2024-01-01 10:01:00"},
+		{"every interval", "@every 90s", "2024-01-01 10:00:30", "2024-01-01 10:02:00"},
+		{"hourly", "@hourly", "2024-01-01 10:07:00", "2024-01-01 11:00:00"},
+		{"daily", "@daily", "2024-01-01 10:07:00", "2024-01-02 00:00:00"},
+		{"weekly is sunday", "@weekly", "2024-01-01 10:07:00", "2024-01-07 00:00:00"},
+		{"minute step", "*/15 * * * *", "2024-01-01 10:07:00", "2024-01-01 10:15:00"},
+		{"exact match is skipped", "*/15 * * * *", "2024-01-01 10:15:00", "2024-01-01 10:30:00"},
+		{"list wraps to next hour", "5,35 * * * *", "2024-01-01 10:35:00", "2024-01-01 11:05:00"},
+		{"value with step", "5/20 * * * *", "2024-01-01 10:30:00", "2024-01-01 10:45:00"},
+		{"hour range with step", "0 9-17/4 * * *", "2024-01-01 10:00:00", "2024-01-01 13:00:00"},
+		{"hour range end", "0 9-17/4 * * *", "2024-01-01 17:00:00", "2024-01-02 09:00:00"},
+		{"time of day passed", "30 2 * * *", "2024-01-01 03:00:00", "2024-01-02 02:30:00"},
+		{"first of month", "0 0 1 * *", "2024-01-15 00:00:00", "2024-02-01 00:00:00"},
+		{"month step", "0 0 1 1-12/3 *", "2024-02-01 00:00:00", "2024-04-01 00:00:00"},
+		{"year rollover", "0 0 1 1 *", "2024-06-01 00:00:00", "2025-01-01 00:00:00"},
+		{"day of week", "0 0 * * 1", "2024-01-01 00:00:00", "2024-01-08 00:00:00"},
+		{"day of week range", "0 0 * * 3-5", "2024-01-01 00:00:00", "2024-01-03 00:00:00"},
+		{"day of month only", "0 0 13 * *", "2024-01-01 00:00:00", "2024-01-13 00:00:00"},
+		{"dom or dow matches friday first", "0 0 13 * 5", "2024-01-01 00:00:00", "2024-01-05 00:00:00"},
+		{"dom or dow matches 13th", "0 0 13 * 5", "2024-01-12 00:00:00", "2024-01-13 00:00:00"},
+		{"dom and wildcard dow", "0 0 13 * *", "2024-01-12 00:00:00", "2024-01-13 00:00:00"},
+		{"leap day", "0 0 29 2 *", "2024-03-01 00:00:00", "2028-02-29 00:00:00"},
+		{"impossible date", "0 0 31 2 *", "2024-01-01 00:00:00", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := Parse(tt.spec)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.spec, err)
+			}
+			got := schedule.Next(at(tt.after))
+			if tt.want == "" {
+				if !got.IsZero() {
+					t.Errorf("Next(%s) = %s, want zero time", tt.after, got)
+				}
+				return
+			}
+			if want := at(tt.want); !got.Equal(want) {
+				t.Errorf("Next(%s) = %s, want %s", tt.after, got, want)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	specs := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"@yearly",
+		"@every",
+		"@every abc",
+		"@every -5m",
+		"@every 0s",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 0 *",
+		"* * * 13 *",
+		"* * * * 7",
+		"*/0 * * * *",
+		"*/-1 * * * *",
+		"*/x * * * *",
+		"5-1 * * * *",
+		"1-x * * * *",
+		"a * * * *",
+		"1,,2 * * * *",
+		"0 0 * * 6-0",
+	}
+
+	for _, spec := range specs {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", spec)
+		}
+	}
+}
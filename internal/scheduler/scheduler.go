@@ -0,0 +1,146 @@
+// Package scheduler uygulama içinde periyodik bakım job'larını çalıştırır
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"elk-stack-user/internal/logger"
+)
+
+// JobFunc zamanlanmış bir job'ın gövdesi
+type JobFunc func(ctx context.Context) error
+
+type Job struct {
+	Name     string
+	Schedule Schedule
+	// Jitter her çalışmaya eklenen [0, Jitter) rastgele gecikme, instance'lar aynı anda uyanmasın diye
+	Jitter  time.Duration
+	Timeout time.Duration
+	Run     JobFunc
+}
+
+type Scheduler struct {
+	locker Locker
+	jobs   []*Job
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New locker nil ise job'lar lock almadan çalışır (tek instance için)
+func New(locker Locker) *Scheduler {
+	return &Scheduler{locker: locker}
+}
+
+// Add cron ifadesini parse edip job'ı kaydeder
+func (s *Scheduler) Add(name, spec string, jitter, timeout time.Duration, run JobFunc) error {
+	schedule, err := Parse(spec)
+	if err != nil {
+		return fmt.Errorf("invalid schedule for job %s: %w", name, err)
+	}
+	s.jobs = append(s.jobs, &Job{
+		Name:     name,
+		Schedule: schedule,
+		Jitter:   jitter,
+		Timeout:  timeout,
+		Run:      run,
+	})
+	return nil
+}
+
+// Start her job için ayrı bir goroutine başlatır
+func (s *Scheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, job)
+	}
+	logger.Logger.Info("Scheduler started", logger.Int("jobs", len(s.jobs)))
+}
+
+// Stop yeni çalışmaları durdurur ve devam eden job'ların bitmesini bekler
+func (s *Scheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+	logger.Logger.Info("Scheduler stopped")
+}
+
+func (s *Scheduler) loop(ctx context.Context, job *Job) {
+	defer s.wg.Done()
+
+	for {
+		tick := job.Schedule.Next(time.Now())
+		if tick.IsZero() {
+			logger.Logger.Error("Scheduled job has no next run time", logger.String("job", job.Name))
+			return
+		}
+		next := tick
+		if job.Jitter > 0 {
+			next = next.Add(time.Duration(rand.Int63n(int64(job.Jitter))))
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			s.run(ctx, job, tick)
+		}
+	}
+}
+
+// run tick jitter eklenmemiş zamanlanmış çalışma anıdır; cron ifadelerinde her instance'ta aynıdır,
+// @every'de lease job'ı aralık başına bir çalışmayla sınırlar
+func (s *Scheduler) run(ctx context.Context, job *Job, tick time.Time) {
+	if s.locker != nil {
+		unlock, ok, err := s.locker.TryLock(ctx, job.Name, tick, job.Schedule.Next(tick))
+		if err != nil {
+			logger.Logger.Error("Scheduled job lock failed", logger.String("job", job.Name), logger.Error(err))
+			return
+		}
+		if !ok {
+			logger.Logger.Debug("Scheduled job skipped - tick already taken by another instance",
+				logger.String("job", job.Name),
+				logger.Time("tick", tick),
+			)
+			return
+		}
+		defer unlock()
+	}
+
+	if job.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, job.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		return job.Run(ctx)
+	}()
+	duration := time.Since(start)
+
+	if err != nil {
+		logger.Logger.Error("Scheduled job failed",
+			logger.String("job", job.Name),
+			logger.Duration("duration", duration),
+			logger.Error(err),
+		)
+		return
+	}
+	logger.Logger.Info("Scheduled job completed",
+		logger.String("job", job.Name),
+		logger.Duration("duration", duration),
+	)
+}
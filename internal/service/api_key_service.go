@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"elk-stack-user/internal/apperror"
+	"elk-stack-user/internal/logger"
+	"elk-stack-user/internal/model"
+	"elk-stack-user/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidAPIKey        = apperror.Unauthorized("invalid or expired API key")
+	ErrAPIKeyNotFound       = apperror.NotFound("API key not found")
+	ErrInvalidAPIKeyRequest = apperror.Validation("invalid API key request")
+)
+
+const (
+	apiKeyPrefix = "uk_"
+	// apiKeyTouchInterval last_used_at her istekte değil en fazla bu aralıkla yazılır
+	apiKeyTouchInterval = time.Minute
+)
+
+// APIKeyService servisler arası erişim için API key'leri yönetir ve doğrular
+type APIKeyService interface {
+	CreateKey(ctx context.Context, adminID uint, req *model.CreateAPIKeyRequest) (*model.APIKeyResponse, error)
+	ListKeys(ctx context.Context) ([]*model.APIKeyResponse, error)
+	RotateKey(ctx context.Context, adminID, id uint) (*model.APIKeyResponse, error)
+	RevokeKey(ctx context.Context, adminID, id uint) error
+	Authenticate(ctx context.Context, rawKey, ipAddress string) (*model.APIKey, error)
+}
+
+type apiKeyService struct {
+	repo repository.APIKeyRepository
+}
+
+func NewAPIKeyService(repo repository.APIKeyRepository) APIKeyService {
+	return &apiKeyService{repo: repo}
+}
+
+func (s *apiKeyService) CreateKey(ctx context.Context, adminID uint, req *model.CreateAPIKeyRequest) (*model.APIKeyResponse, error) {
+	for _, scope := range req.Scopes {
+		if !model.IsValidScope(scope) {
+			return nil, fmt.Errorf("%w: unknown scope %q", ErrInvalidAPIKeyRequest, scope)
+		}
+	}
+
+	prefix, secret := generateAPIKeyParts()
+	key := &model.APIKey{
+		Name:       req.Name,
+		Prefix:     prefix,
+		SecretHash: hashToken(secret),
+		Scopes:     strings.Join(req.Scopes, ","),
+		CreatedBy:  &adminID,
+	}
+	if req.Duration != "" {
+		duration, err := time.ParseDuration(req.Duration)
+		if err != nil || duration <= 0 {
+			return nil, fmt.Errorf("%w: duration must be a positive Go duration like 720h", ErrInvalidAPIKeyRequest)
+		}
+		expiresAt := time.Now().Add(duration)
+		key.ExpiresAt = &expiresAt
+	}
+
+	if err := s.repo.Create(ctx, key); err != nil {
+		return nil, err
+	}
+
+	logger.Logger.Warn("API key created by admin",
+		logger.SecurityEvent("api_key_created"),
+		logger.Uint("admin_id", adminID),
+		logger.Uint("api_key_id", key.ID),
+		logger.String("name", key.Name),
+		logger.String("scopes", key.Scopes),
+	)
+
+	response := toAPIKeyResponse(key)
+	response.Key = formatAPIKey(prefix, secret)
+	return response, nil
+}
+
+func (s *apiKeyService) ListKeys(ctx context.Context) ([]*model.APIKeyResponse, error) {
+	keys, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]*model.APIKeyResponse, len(keys))
+	for i, key := range keys {
+		responses[i] = toAPIKeyResponse(key)
+	}
+	return responses, nil
+}
+
+// RotateKey yeni bir anahtar üretir, eski anahtar hemen geçersiz olur
+func (s *apiKeyService) RotateKey(ctx context.Context, adminID, id uint) (*model.APIKeyResponse, error) {
+	prefix, secret := generateAPIKeyParts()
+	if err := s.repo.Rotate(ctx, id, prefix, hashToken(secret)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+
+	key, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Logger.Warn("API key rotated by admin",
+		logger.SecurityEvent("api_key_rotated"),
+		logger.Uint("admin_id", adminID),
+		logger.Uint("api_key_id", key.ID),
+		logger.String("name", key.Name),
+	)
+
+	response := toAPIKeyResponse(key)
+	response.Key = formatAPIKey(prefix, secret)
+	return response, nil
+}
+
+func (s *apiKeyService) RevokeKey(ctx context.Context, adminID, id uint) error {
+	if err := s.repo.Revoke(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAPIKeyNotFound
+		}
+		return err
+	}
+
+	logger.Logger.Warn("API key revoked by admin",
+		logger.SecurityEvent("api_key_revoked"),
+		logger.Uint("admin_id", adminID),
+		logger.Uint("api_key_id", id),
+	)
+	return nil
+}
+
+func (s *apiKeyService) Authenticate(ctx context.Context, rawKey, ipAddress string) (*model.APIKey, error) {
+	prefix, secret, ok := parseAPIKey(rawKey)
+	if !ok {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := s.repo.GetByPrefix(ctx, prefix)
+	if err != nil {
+		return nil, ErrInvalidAPIKey
+	}
+	if subtle.ConstantTimeCompare([]byte(hashToken(secret)), []byte(key.SecretHash)) != 1 {
+		return nil, ErrInvalidAPIKey
+	}
+
+	now := time.Now()
+	if key.RevokedAt != nil || (key.ExpiresAt != nil && now.After(*key.ExpiresAt)) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > apiKeyTouchInterval || key.LastUsedIP != ipAddress {
+		if err := s.repo.TouchLastUsed(ctx, key.ID, ipAddress, now); err != nil {
+			logger.Logger.Warn("Failed to update API key last use", logger.Uint("api_key_id", key.ID), logger.Error(err))
+		}
+	}
+	return key, nil
+}
+
+func generateAPIKeyParts() (prefix, secret string) {
+	return generateRandomString(12), generateRandomString(64)
+}
+
+func formatAPIKey(prefix, secret string) string {
+	return apiKeyPrefix + prefix + "_" + secret
+}
+
+func parseAPIKey(raw string) (prefix, secret string, ok bool) {
+	rest, found := strings.CutPrefix(raw, apiKeyPrefix)
+	if !found {
+		return "", "", false
+	}
+	prefix, secret, found = strings.Cut(rest, "_")
+	return prefix, secret, found && prefix != "" && secret != ""
+}
+
+func toAPIKeyResponse(key *model.APIKey) *model.APIKeyResponse {
+	return &model.APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.ScopeList(),
+		CreatedBy:  key.CreatedBy,
+		CreatedAt:  key.CreatedAt,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		LastUsedIP: key.LastUsedIP,
+		RotatedAt:  key.RotatedAt,
+		RevokedAt:  key.RevokedAt,
+	}
+}
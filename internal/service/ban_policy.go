@@ -0,0 +1,132 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	"elk-stack-user/internal/model"
+)
+
+// BanThreshold bir anahtar (username veya IP) için Window içinde izin verilen başarısız deneme sayısı.
+// MaxAttempts 0 ise bu anahtar için ban uygulanmaz.
+type BanThreshold struct {
+	MaxAttempts int
+	Window      time.Duration
+}
+
+func (t BanThreshold) Enabled() bool {
+	return t.MaxAttempts > 0 && t.Window > 0
+}
+
+// BanPolicy başarısız login denemelerinde uygulanan ban kuralları.
+// Eşikler birbirinden bağımsızdır: (username, ip) çifti düşük eşikle sadece saldırganı bloklar,
+// ip eşiği daha yüksek tutularak NAT arkasındaki herkesin kolayca kilitlenmesi engellenir.
+// Sadece username'e bağlı ban herhangi birinin kurbanı IP değiştirerek kilitlemesine izin
+// verdiği için varsayılan olarak kapalıdır.
+type BanPolicy struct {
+	UsernameIP BanThreshold
+	Username   BanThreshold
+	IP         BanThreshold
+	// Durations tekrar eden ihlallerde sırayla uygulanan ban süreleri, son değer üst sınırdır
+	Durations []time.Duration
+	// EscalationWindow bu süre içindeki önceki banlar tekrar ihlal sayılır
+	EscalationWindow time.Duration
+	// DryRun true ise ban kaydedilmez, sadece loglanır (eşikleri Kibana'da ayarlamak için)
+	DryRun bool
+	// ExtendOnAttempt true ise ban süresince yapılan her deneme banı orijinal süresi kadar uzatır
+	ExtendOnAttempt bool
+}
+
+var defaultBanDurations = []time.Duration{2 * time.Minute, 10 * time.Minute, time.Hour, 24 * time.Hour}
+
+func NewBanPolicy() *BanPolicy {
+	return &BanPolicy{
+		UsernameIP: BanThreshold{
+			MaxAttempts: getEnvInt("BAN_USERNAME_IP_MAX_ATTEMPTS", 3),
+			Window:      getEnvDuration("BAN_USERNAME_IP_WINDOW", 2*time.Minute),
+		},
+		Username: BanThreshold{
+			MaxAttempts: getEnvInt("BAN_USERNAME_MAX_ATTEMPTS", 0),
+			Window:      getEnvDuration("BAN_USERNAME_WINDOW", 10*time.Minute),
+		},
+		IP: BanThreshold{
+			MaxAttempts: getEnvInt("BAN_IP_MAX_ATTEMPTS", 10),
+			Window:      getEnvDuration("BAN_IP_WINDOW", 2*time.Minute),
+		},
+		Durations:        getEnvDurations("BAN_DURATIONS", defaultBanDurations),
+		EscalationWindow: getEnvDuration("BAN_ESCALATION_WINDOW", 24*time.Hour),
+		DryRun:           getEnvBool("BAN_DRY_RUN", false),
+		ExtendOnAttempt:  getEnvBool("BAN_EXTEND_ON_ATTEMPT", false),
+	}
+}
+
+// Threshold ban tipine ait eşiği döner
+func (p *BanPolicy) Threshold(banType string) BanThreshold {
+	switch banType {
+	case model.BanTypeUsername:
+		return p.Username
+	case model.BanTypeIP:
+		return p.IP
+	default:
+		return p.UsernameIP
+	}
+}
+
+// Duration daha önce EscalationWindow içinde alınmış ban sayısına göre uygulanacak süreyi döner
+func (p *BanPolicy) Duration(previousBans int64) time.Duration {
+	if len(p.Durations) == 0 {
+		return defaultBanDurations[0]
+	}
+	if previousBans >= int64(len(p.Durations)) {
+		return p.Durations[len(p.Durations)-1]
+	}
+	return p.Durations[previousBans]
+}
+
+// BanError aktif bir ban yüzünden reddedilen login'i bitiş zamanıyla birlikte taşır,
+// errors.Is(err, ErrAccountBanned) ile yakalanır
+type BanError struct {
+	ExpiresAt time.Time
+}
+
+func (e *BanError) Error() string {
+	return ErrAccountBanned.Error()
+}
+
+func (e *BanError) Unwrap() error {
+	return ErrAccountBanned
+}
+
+// Details ban bitişini yanıta ekler
+func (e *BanError) Details() map[string]interface{} {
+	return map[string]interface{}{
+		"expires_at":  e.ExpiresAt.UTC(),
+		"retry_after": e.RetryAfter(time.Now()),
+	}
+}
+
+// RetryAfter Retry-After header'ı için kalan süreyi yukarı yuvarlanmış saniye olarak döner
+func (e *BanError) RetryAfter(now time.Time) int64 {
+	remaining := e.ExpiresAt.Sub(now)
+	if remaining <= 0 {
+		return 1
+	}
+	return int64((remaining + time.Second - 1) / time.Second)
+}
+
+// getEnvDurations "2m,10m,1h" formatındaki listeyi parse eder, hatalı değer varsa default döner
+func getEnvDurations(key string, defaultValue []time.Duration) []time.Duration {
+	value := getEnv(key, "")
+	if value == "" {
+		return defaultValue
+	}
+	var durations []time.Duration
+	for _, part := range strings.Split(value, ",") {
+		d, err := time.ParseDuration(strings.TrimSpace(part))
+		if err != nil || d <= 0 {
+			return defaultValue
+		}
+		durations = append(durations, d)
+	}
+	return durations
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const breachedPrefixLength = 5
+
+// BreachedPasswordList sızdırılmış şifrelerin SHA-1 hash'lerini Have I Been Pwned'in
+// k-anonymity range API'si gibi 5 karakterlik prefix'lere bölünmüş olarak bellekte tutar.
+//
+// İki format desteklenir:
+//   - tek dosya: her satırda tam SHA-1 hash'i, opsiyonel ":COUNT" ile
+//   - dizin: "<PREFIX>.txt" dosyaları, her satırda 35 karakterlik suffix ve opsiyonel ":COUNT"
+//     (HIBP range dosyalarının indirildiği düzen)
+type BreachedPasswordList struct {
+	buckets map[string][]string
+	count   int
+}
+
+func LoadBreachedPasswordList(path string) (*BreachedPasswordList, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached password list: %w", err)
+	}
+
+	list := &BreachedPasswordList{buckets: make(map[string][]string)}
+	if info.IsDir() {
+		files, err := filepath.Glob(filepath.Join(path, "*.txt"))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			prefix := strings.ToUpper(strings.TrimSuffix(filepath.Base(file), ".txt"))
+			if len(prefix) != breachedPrefixLength {
+				continue
+			}
+			if err := list.loadFile(file, prefix); err != nil {
+				return nil, err
+			}
+		}
+	} else if err := list.loadFile(path, ""); err != nil {
+		return nil, err
+	}
+
+	for prefix := range list.buckets {
+		sort.Strings(list.buckets[prefix])
+	}
+	return list, nil
+}
+
+func (l *BreachedPasswordList) loadFile(path, prefix string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open breached password list: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		hash, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		hash = strings.ToUpper(prefix + hash)
+		if len(hash) != sha1.Size*2 {
+			continue
+		}
+		bucket := hash[:breachedPrefixLength]
+		l.buckets[bucket] = append(l.buckets[bucket], hash[breachedPrefixLength:])
+		l.count++
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read breached password list: %w", err)
+	}
+	return nil
+}
+
+// Contains şifrenin SHA-1 hash'i listede var mı kontrol eder
+func (l *BreachedPasswordList) Contains(password string) bool {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	suffixes := l.buckets[hash[:breachedPrefixLength]]
+	suffix := hash[breachedPrefixLength:]
+	i := sort.SearchStrings(suffixes, suffix)
+	return i < len(suffixes) && suffixes[i] == suffix
+}
+
+// Len listedeki hash sayısı
+func (l *BreachedPasswordList) Len() int {
+	return l.count
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"elk-stack-user/internal/oidc"
+	"elk-stack-user/internal/passhash"
+)
+
+type Config struct {
+	SessionTTL       time.Duration
+	PasswordResetTTL time.Duration
+	// AppBaseURL e-postalardaki linkler için kullanılır
+	AppBaseURL string
+	// PasswordResetURL sıfırlama e-postasındaki link, token query parametresi olarak eklenir.
+	// Varsayılan servisin kendi GET /password/reset formudur, frontend varsa onun sayfası verilir.
+	PasswordResetURL string
+
+	// MailQueue forgot/resend/kayıt maillerini istek dışında gönderir, main'de oluşturulup
+	// shutdown'da durdurulur
+	MailQueue        *MailQueue
+	MailQueueWorkers int
+	MailQueueSize    int
+	// MailRateLimitPerEmail ve MailRateLimitPerIP forgot/resend isteklerinin
+	// MailRateLimitWindow başına üst sınırı, 0 sınırı kapatır
+	MailRateLimitPerEmail int
+	MailRateLimitPerIP    int
+	MailRateLimitWindow   time.Duration
+
+	EmailVerificationTTL            time.Duration
+	EmailVerificationResendInterval time.Duration
+	// RequireEmailVerification true ise doğrulanmamış hesaplar login olamaz
+	RequireEmailVerification bool
+
+	// MFAIssuer authenticator uygulamasında görünen isim
+	MFAIssuer       string
+	MFAChallengeTTL time.Duration
+
+	// HideAccountStatus true ise pasif hesaplar bilinmeyen kullanıcıyla aynı "invalid credentials" hatasını alır
+	HideAccountStatus bool
+
+	// CursorSecret liste cursor'larını imzalar, boşsa açılışta rastgele üretilir
+	CursorSecret string
+	// MaxPageSize liste endpoint'lerinde izin verilen en büyük page_size
+	MaxPageSize int
+
+	BanPolicy      *BanPolicy
+	PasswordPolicy *PasswordPolicy
+	PasswordHasher *passhash.Manager
+	// BreachedPasswordsFile boş değilse açılışta yüklenip PasswordPolicy.Breached'e atanır
+	BreachedPasswordsFile string
+
+	// OIDC provider
+	OIDCIssuer         string
+	OIDCAuthCodeTTL    time.Duration
+	OIDCAccessTokenTTL time.Duration
+	OIDCIDTokenTTL     time.Duration
+	// OIDCSigningKeyFile ID token'ları imzalayan RSA key (PEM), boşsa açılışta geçici key üretilir
+	OIDCSigningKeyFile string
+	OIDCSigner         *oidc.Signer
+}
+
+func NewConfig() *Config {
+	config := &Config{
+		SessionTTL:       getEnvDuration("SESSION_TTL", 24*time.Hour),
+		PasswordResetTTL: getEnvDuration("PASSWORD_RESET_TTL", 30*time.Minute),
+		AppBaseURL:       strings.TrimRight(getEnv("APP_BASE_URL", "http://localhost:8080"), "/"),
+
+		MailQueueWorkers:      getEnvInt("MAIL_QUEUE_WORKERS", 4),
+		MailQueueSize:         getEnvInt("MAIL_QUEUE_SIZE", 1000),
+		MailRateLimitPerEmail: getEnvInt("MAIL_RATE_LIMIT_PER_EMAIL", 5),
+		MailRateLimitPerIP:    getEnvInt("MAIL_RATE_LIMIT_PER_IP", 20),
+		MailRateLimitWindow:   getEnvDuration("MAIL_RATE_LIMIT_WINDOW", time.Hour),
+
+		EmailVerificationTTL:            getEnvDuration("EMAIL_VERIFICATION_TTL", 24*time.Hour),
+		EmailVerificationResendInterval: getEnvDuration("EMAIL_VERIFICATION_RESEND_INTERVAL", time.Minute),
+		RequireEmailVerification:        getEnvBool("REQUIRE_EMAIL_VERIFICATION", false),
+
+		MFAIssuer:       getEnv("MFA_ISSUER", "User Service"),
+		MFAChallengeTTL: getEnvDuration("MFA_CHALLENGE_TTL", 5*time.Minute),
+
+		HideAccountStatus: getEnvBool("LOGIN_HIDE_ACCOUNT_STATUS", false),
+
+		CursorSecret: getEnv("CURSOR_SECRET", ""),
+		MaxPageSize:  getEnvInt("MAX_PAGE_SIZE", 100),
+
+		BanPolicy:             NewBanPolicy(),
+		PasswordPolicy:        NewPasswordPolicy(),
+		PasswordHasher:        passhash.Default(),
+		BreachedPasswordsFile: getEnv("PASSWORD_BREACHED_LIST_FILE", ""),
+
+		OIDCAuthCodeTTL:    getEnvDuration("OIDC_AUTH_CODE_TTL", 5*time.Minute),
+		OIDCAccessTokenTTL: getEnvDuration("OIDC_ACCESS_TOKEN_TTL", time.Hour),
+		OIDCIDTokenTTL:     getEnvDuration("OIDC_ID_TOKEN_TTL", time.Hour),
+		OIDCSigningKeyFile: getEnv("OIDC_SIGNING_KEY_FILE", ""),
+	}
+	config.PasswordResetURL = getEnv("PASSWORD_RESET_URL", config.AppBaseURL+"/password/reset")
+	config.OIDCIssuer = strings.TrimRight(getEnv("OIDC_ISSUER", config.AppBaseURL), "/")
+	return config
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if d, err := time.ParseDuration(getEnv(key, "")); err == nil {
+		return d
+	}
+	return defaultValue
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if b, err := strconv.ParseBool(getEnv(key, "")); err == nil {
+		return b
+	}
+	return defaultValue
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	if i, err := strconv.Atoi(getEnv(key, "")); err == nil {
+		return i
+	}
+	return defaultValue
+}
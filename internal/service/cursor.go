@@ -0,0 +1,124 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"elk-stack-user/internal/apperror"
+	"elk-stack-user/internal/model"
+)
+
+var ErrInvalidCursor = apperror.Validation("invalid or expired cursor")
+
+// cursorPayload cursor'ın imzalanan içeriği. Filter alanı cursor'ı üretildiği
+// filtre ve sıralamaya bağlar, farklı bir sorguda kullanılırsa reddedilir.
+type cursorPayload struct {
+	Values   []json.RawMessage `json:"v"`
+	Backward bool              `json:"b,omitempty"`
+	Filter   string            `json:"f"`
+}
+
+// cursorCodec keyset cursor'larını base64url(payload).base64url(hmac) olarak kodlar
+type cursorCodec struct {
+	secret []byte
+}
+
+func newCursorCodec(secret string) *cursorCodec {
+	return &cursorCodec{secret: []byte(secret)}
+}
+
+// Encode kullanıcının sıralama anahtarlarından cursor üretir
+func (c *cursorCodec) Encode(filter *model.UserFilter, user *model.User, backward bool) (string, error) {
+	payload := cursorPayload{Backward: backward, Filter: filterFingerprint(filter)}
+	for _, key := range model.UserSortKeys(filter.Sort) {
+		raw, err := json.Marshal(user.SortValue(key.Field))
+		if err != nil {
+			return "", err
+		}
+		payload.Values = append(payload.Values, raw)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(data)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(c.sign(encoded)), nil
+}
+
+// Decode imzayı doğrular ve değerleri kolon tiplerine çevirir
+func (c *cursorCodec) Decode(filter *model.UserFilter, cursor string) (*model.UserCursor, error) {
+	encoded, signature, ok := strings.Cut(cursor, ".")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, c.sign(encoded)) {
+		return nil, ErrInvalidCursor
+	}
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var payload cursorPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if payload.Filter != filterFingerprint(filter) {
+		return nil, ErrInvalidCursor
+	}
+
+	keys := model.UserSortKeys(filter.Sort)
+	if len(payload.Values) != len(keys) {
+		return nil, ErrInvalidCursor
+	}
+	result := &model.UserCursor{Backward: payload.Backward}
+	for i, key := range keys {
+		value, err := decodeSortValue(key.Field, payload.Values[i])
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		result.Values = append(result.Values, value)
+	}
+	return result, nil
+}
+
+func (c *cursorCodec) sign(encoded string) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
+
+// decodeSortValue JSON değerini sıralama kolonunun Go tipine çevirir
+func decodeSortValue(field string, raw json.RawMessage) (interface{}, error) {
+	switch field {
+	case "id":
+		var v uint
+		err := json.Unmarshal(raw, &v)
+		return v, err
+	case "age":
+		var v int
+		err := json.Unmarshal(raw, &v)
+		return v, err
+	case "created_at", "updated_at":
+		var v time.Time
+		err := json.Unmarshal(raw, &v)
+		return v, err
+	default:
+		var v string
+		err := json.Unmarshal(raw, &v)
+		return v, err
+	}
+}
+
+// filterFingerprint filtre ve sıralamanın kısa özeti
+func filterFingerprint(filter *model.UserFilter) string {
+	data, _ := json.Marshal(filter)
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:12])
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"elk-stack-user/internal/model"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	created := time.Date(2024, 3, 1, 12, 30, 45, 123456000, time.UTC)
+	user := &model.User{ID: 42, Username: "alice", Email: "alice@example.com", Age: 31}
+	user.CreatedAt = created
+
+	active := true
+	tests := []struct {
+		name     string
+		filter   *model.UserFilter
+		backward bool
+		want     []interface{}
+	}{
+		{name: "default sort", filter: &model.UserFilter{}, want: []interface{}{uint(42)}},
+		{name: "backward", filter: &model.UserFilter{}, backward: true, want: []interface{}{uint(42)}},
+		{
+			name:   "string key",
+			filter: &model.UserFilter{Sort: []model.SortField{{Field: "username", Desc: true}}},
+			want:   []interface{}{"alice", uint(42)},
+		},
+		{
+			name:   "int and time keys with filters",
+			filter: &model.UserFilter{IsActive: &active, EmailPrefix: "al", Sort: []model.SortField{{Field: "age"}, {Field: "created_at", Desc: true}}},
+			want:   []interface{}{31, created, uint(42)},
+		},
+		{
+			name:   "explicit id ends keys",
+			filter: &model.UserFilter{Sort: []model.SortField{{Field: "id", Desc: true}, {Field: "email"}}},
+			want:   []interface{}{uint(42)},
+		},
+	}
+
+	codec := newCursorCodec("test-secret")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor, err := codec.Encode(tt.filter, user, tt.backward)
+			if err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			got, err := codec.Decode(tt.filter, cursor)
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if got.Backward != tt.backward {
+				t.Errorf("Backward = %v, want %v", got.Backward, tt.backward)
+			}
+			if len(got.Values) != len(tt.want) {
+				t.Fatalf("Values = %v, want %v", got.Values, tt.want)
+			}
+			for i, want := range tt.want {
+				if wantTime, ok := want.(time.Time); ok {
+					if gotTime, ok := got.Values[i].(time.Time); !ok || !gotTime.Equal(wantTime) {
+						t.Errorf("Values[%d] = %v, want %v", i, got.Values[i], want)
+					}
+					continue
+				}
+				if got.Values[i] != want {
+					t.Errorf("Values[%d] = %#v, want %#v", i, got.Values[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestCursorRejected(t *testing.T) {
+	byUsername := &model.UserFilter{Sort: []model.SortField{{Field: "username"}}}
+	user := &model.User{ID: 7, Username: "bob", Age: 20}
+
+	codec := newCursorCodec("test-secret")
+	cursor, err := codec.Encode(byUsername, user, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, signature, _ := strings.Cut(cursor, ".")
+
+	// forge geçerli imzalı ama içeriği beklenmeyen bir cursor üretir
+	forge := func(values ...string) string {
+		p := cursorPayload{Filter: filterFingerprint(byUsername)}
+		for _, v := range values {
+			p.Values = append(p.Values, json.RawMessage(v))
+		}
+		data, _ := json.Marshal(p)
+		encoded := base64.RawURLEncoding.EncodeToString(data)
+		return encoded + "." + base64.RawURLEncoding.EncodeToString(codec.sign(encoded))
+	}
+
+	active := false
+	tests := []struct {
+		name   string
+		codec  *cursorCodec
+		filter *model.UserFilter
+		cursor string
+	}{
+		{name: "tampered payload", cursor: flipFirstChar(payload) + "." + signature},
+		{name: "tampered signature", cursor: payload + "." + flipFirstChar(signature)},
+		{name: "missing signature", cursor: payload},
+		{name: "signature not base64", cursor: payload + ".!!!"},
+		{name: "other secret", codec: newCursorCodec("other-secret"), cursor: cursor},
+		{name: "different sort key", filter: &model.UserFilter{Sort: []model.SortField{{Field: "age"}}}, cursor: cursor},
+		{name: "different sort direction", filter: &model.UserFilter{Sort: []model.SortField{{Field: "username", Desc: true}}}, cursor: cursor},
+		{name: "different filter", filter: &model.UserFilter{IsActive: &active, Sort: byUsername.Sort}, cursor: cursor},
+		{name: "wrong value count", cursor: forge(`"bob"`)},
+		{name: "wrong value type", cursor: forge(`"bob"`, `"seven"`)},
+		{name: "empty", cursor: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, filter := codec, byUsername
+			if tt.codec != nil {
+				c = tt.codec
+			}
+			if tt.filter != nil {
+				filter = tt.filter
+			}
+			if _, err := c.Decode(filter, tt.cursor); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("Decode error = %v, want %v", err, ErrInvalidCursor)
+			}
+		})
+	}
+
+	// Kontrol: forge'un ürettiği doğru biçimli cursor kabul edilir
+	if _, err := codec.Decode(byUsername, forge(`"bob"`, `7`)); err != nil {
+		t.Errorf("well-formed forged cursor rejected: %v", err)
+	}
+}
+
+// flipFirstChar base64url metnin ilk karakterini başka geçerli bir karakterle değiştirir.
+// Son karakter kullanılmayan dolgu bitleri taşıyabildiği için değişmeden aynı byte'lara açılabilir.
+func flipFirstChar(s string) string {
+	replacement := "A"
+	if s[0] == 'A' {
+		replacement = "B"
+	}
+	return replacement + s[1:]
+}
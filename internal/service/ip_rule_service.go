@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"elk-stack-user/internal/apperror"
+	"elk-stack-user/internal/ipacl"
+	"elk-stack-user/internal/logger"
+	"elk-stack-user/internal/model"
+	"elk-stack-user/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrIPRuleNotFound = apperror.NotFound("ip rule not found")
+	ErrInvalidIPRule  = apperror.Validation("invalid ip rule")
+	// ErrIPRuleLockout allow kuralı eklendiğinde isteği yapan adminin IP'si /admin'e erişemeyecekti
+	ErrIPRuleLockout = apperror.Validation("allow rule does not cover your current IP and would lock you out of admin routes")
+)
+
+// IPRuleService veritabanı kaynaklı IP allow/deny kurallarını yönetir,
+// her değişiklikten sonra bu instance'ın ACL'ini hemen yeniler
+type IPRuleService interface {
+	ListRules(ctx context.Context) ([]*model.IPRule, error)
+	// CreateRule ipAddress isteği yapan adminin IP'si, allow kuralları bu IP'yi dışarıda bırakamaz
+	CreateRule(ctx context.Context, adminID uint, ipAddress string, req *model.CreateIPRuleRequest) (*model.IPRule, error)
+	DeleteRule(ctx context.Context, adminID, id uint) error
+}
+
+type ipRuleService struct {
+	repo repository.IPRuleRepository
+	acl  *ipacl.ACL
+}
+
+func NewIPRuleService(repo repository.IPRuleRepository, acl *ipacl.ACL) IPRuleService {
+	return &ipRuleService{repo: repo, acl: acl}
+}
+
+func (s *ipRuleService) ListRules(ctx context.Context) ([]*model.IPRule, error) {
+	return s.repo.ListActive(ctx)
+}
+
+func (s *ipRuleService) CreateRule(ctx context.Context, adminID uint, ipAddress string, req *model.CreateIPRuleRequest) (*model.IPRule, error) {
+	prefix, err := ipacl.ParsePrefix(req.CIDR)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIPRule, err)
+	}
+	// ADMIN_IP_ALLOW_LIST boşken ilk allow kuralı /admin'i kısıtlamaya başlar;
+	// adminin kendi IP'sini dışarıda bırakan kural kaydedilmez
+	if req.Action == model.IPRuleAllow && !s.acl.AdminAllowedWith(ipAddress, prefix) {
+		logger.Logger.Warn("IP allow rule rejected, would lock out admin",
+			logger.SecurityEvent("admin_ip_rule_lockout"),
+			logger.Uint("admin_id", adminID),
+			logger.ClientIP(ipAddress),
+			logger.String("cidr", prefix.String()),
+		)
+		return nil, ErrIPRuleLockout
+	}
+
+	rule := &model.IPRule{
+		CIDR:      prefix.String(),
+		Action:    req.Action,
+		Note:      req.Note,
+		CreatedBy: &adminID,
+	}
+	if req.Duration != "" {
+		duration, err := time.ParseDuration(req.Duration)
+		if err != nil || duration <= 0 {
+			return nil, fmt.Errorf("%w: duration must be a positive Go duration like 24h", ErrInvalidIPRule)
+		}
+		expiresAt := time.Now().Add(duration)
+		rule.ExpiresAt = &expiresAt
+	}
+
+	if err := s.repo.Create(ctx, rule); err != nil {
+		return nil, err
+	}
+	s.refresh(ctx)
+
+	logger.Logger.Warn("IP rule created by admin",
+		logger.SecurityEvent("admin_ip_rule_created"),
+		logger.Uint("admin_id", adminID),
+		logger.Uint("rule_id", rule.ID),
+		logger.String("cidr", rule.CIDR),
+		logger.String("action", rule.Action),
+		logger.String("note", rule.Note),
+	)
+	return rule, nil
+}
+
+func (s *ipRuleService) DeleteRule(ctx context.Context, adminID, id uint) error {
+	rule, err := s.repo.Delete(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrIPRuleNotFound
+		}
+		return err
+	}
+	s.refresh(ctx)
+
+	logger.Logger.Warn("IP rule deleted by admin",
+		logger.SecurityEvent("admin_ip_rule_deleted"),
+		logger.Uint("admin_id", adminID),
+		logger.Uint("rule_id", rule.ID),
+		logger.String("cidr", rule.CIDR),
+		logger.String("action", rule.Action),
+	)
+	return nil
+}
+
+func (s *ipRuleService) refresh(ctx context.Context) {
+	if err := s.acl.Refresh(ctx); err != nil {
+		logger.Logger.Error("Failed to refresh IP rules", logger.Error(err))
+	}
+}
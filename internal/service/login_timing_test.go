@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	"elk-stack-user/internal/apperror"
+	"elk-stack-user/internal/model"
+	"elk-stack-user/internal/passhash"
+	"elk-stack-user/internal/repository"
+)
+
+// timingUserRepository Login'in kullandığı metotları bellekte karşılar. Diğer metotlar
+// gömülü nil interface'e düşer ve çağrılırsa test panic ile başarısız olur.
+type timingUserRepository struct {
+	repository.UserRepository
+	user *model.User
+}
+
+func (r *timingUserRepository) GetUserForLogin(ctx context.Context, login string) (*model.User, error) {
+	if login == r.user.Username {
+		return r.user, nil
+	}
+	return nil, apperror.NotFound("user not found")
+}
+
+func (r *timingUserRepository) SamplePasswordHash(ctx context.Context, seed uint32) (string, error) {
+	return r.user.Password, nil
+}
+
+func (r *timingUserRepository) IsBanned(ctx context.Context, username, ipAddress string) (*model.BanRecord, error) {
+	return nil, nil
+}
+
+func (r *timingUserRepository) RecordLoginAttempt(ctx context.Context, attempt *model.LoginAttempt) error {
+	return nil
+}
+
+// ksCritical001 iki örneklemli Kolmogorov-Smirnov testinin alpha=0.001 katsayısı
+const ksCritical001 = 1.949
+
+// ksStatistic iki örneklemin ampirik dağılım fonksiyonları arasındaki en büyük fark
+func ksStatistic(a, b []float64) float64 {
+	sort.Float64s(a)
+	sort.Float64s(b)
+	var d float64
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		x := math.Min(a[i], b[j])
+		for i < len(a) && a[i] <= x {
+			i++
+		}
+		for j < len(b) && b[j] <= x {
+			j++
+		}
+		d = math.Max(d, math.Abs(float64(i)/float64(len(a))-float64(j)/float64(len(b))))
+	}
+	return d
+}
+
+// TestLoginTimingUnknownUserMatchesWrongPassword bilinmeyen kullanıcı ve yanlış şifre
+// login'lerinin süre dağılımlarının ayırt edilemediğini KS testiyle doğrular. Hesabın hash'i
+// hâlâ eski algoritmada (bcrypt) iken güncel hasher argon2id'dir; dummy doğrulama güncel
+// hasher'a sabit olsaydı iki dağılım belirgin şekilde ayrışırdı.
+//
+// Süre ölçümü yüklü CI makinelerinde gürültülü olduğu için test varsayılan olarak atlanır:
+//
+//	TIMING_TESTS=1 go test -run LoginTiming ./internal/service
+func TestLoginTimingUnknownUserMatchesWrongPassword(t *testing.T) {
+	if os.Getenv("TIMING_TESTS") != "1" {
+		t.Skip("timing test skipped, set TIMING_TESTS=1 to run it")
+	}
+
+	bcryptHasher := passhash.NewBcryptHasher(8)
+	argon2idHasher := passhash.NewArgon2idHasher(passhash.Argon2idParams{
+		Memory: 8 * 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32,
+	})
+	legacyHash, err := bcryptHasher.Hash("correct horse battery staple")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo := &timingUserRepository{user: &model.User{ID: 1, Username: "alice", Password: legacyHash, IsActive: true}}
+	svc := NewUserService(repo, nil, nil, nil, nil, &Config{
+		BanPolicy:      &BanPolicy{},
+		PasswordHasher: passhash.NewManager(argon2idHasher, bcryptHasher),
+	})
+
+	login := func(username string) float64 {
+		start := time.Now()
+		_, err := svc.Login(context.Background(), &model.LoginRequest{Username: username, Password: "wrong password"}, "192.0.2.1", "test")
+		elapsed := time.Since(start)
+		if err != ErrInvalidCredentials {
+			t.Fatalf("login %q: got %v, want ErrInvalidCredentials", username, err)
+		}
+		return float64(elapsed.Microseconds())
+	}
+
+	// Isınma: ilk çağrılar (allocator, CPU frekansı) ölçüme girmesin
+	for i := 0; i < 5; i++ {
+		login("alice")
+		login("mallory")
+	}
+
+	// Örnekler karışık sırayla alınır, zamanla değişen yük iki tarafı eşit etkiler
+	const samples = 80
+	var known, unknown []float64
+	order := rand.Perm(2 * samples)
+	for _, i := range order {
+		if i%2 == 0 {
+			known = append(known, login("alice"))
+		} else {
+			unknown = append(unknown, login("mallory"))
+		}
+	}
+
+	d := ksStatistic(known, unknown)
+	n, m := float64(len(known)), float64(len(unknown))
+	critical := ksCritical001 * math.Sqrt((n+m)/(n*m))
+	if d > critical {
+		t.Errorf("login timings are distinguishable: KS D=%.3f > %.3f (median known %.0fµs, unknown %.0fµs)",
+			d, critical, known[len(known)/2], unknown[len(unknown)/2])
+	}
+}
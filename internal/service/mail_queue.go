@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"elk-stack-user/internal/logger"
+)
+
+// ErrMailQueueFull kuyruk doluyken veya kapatılmışken eklenen işler için döner
+var ErrMailQueueFull = errors.New("mail queue is full")
+
+// MailQueue e-posta işlerini (token yazımı + gönderim) sınırlı bir kuyrukta sabit sayıda
+// worker ile isteğin dışında çalıştırır. Stop kuyruktaki işlerin bitmesini bekler, böylece
+// deploy sırasında kabul edilmiş mailler kaybolmaz.
+type MailQueue struct {
+	tasks   chan mailTask
+	timeout time.Duration
+	wg      sync.WaitGroup
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+type mailTask struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// NewMailQueue worker'ları başlatır. timeout tek bir işin (DB yazımı + SMTP) süre sınırıdır.
+func NewMailQueue(workers, size int, timeout time.Duration) *MailQueue {
+	if workers < 1 {
+		workers = 1
+	}
+	q := &MailQueue{
+		tasks:   make(chan mailTask, size),
+		timeout: timeout,
+	}
+	for i := 0; i < workers; i++ {
+		q.wg.Add(1)
+		go q.work()
+	}
+	return q
+}
+
+// Enqueue işi bloklamadan kuyruğa ekler, kuyruk doluysa ErrMailQueueFull döner
+func (q *MailQueue) Enqueue(name string, run func(ctx context.Context) error) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return ErrMailQueueFull
+	}
+	select {
+	case q.tasks <- mailTask{name: name, run: run}:
+		return nil
+	default:
+		return ErrMailQueueFull
+	}
+}
+
+// Stop yeni işleri reddeder ve kuyruktaki işlerin bitmesini ctx bitene kadar bekler
+func (q *MailQueue) Stop(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.tasks)
+	}
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		logger.Logger.Warn("Mail queue stopped before draining", logger.Int("pending", len(q.tasks)))
+		return ctx.Err()
+	}
+}
+
+func (q *MailQueue) work() {
+	defer q.wg.Done()
+	for task := range q.tasks {
+		q.run(task)
+	}
+}
+
+func (q *MailQueue) run(task mailTask) {
+	ctx, cancel := context.WithTimeout(context.Background(), q.timeout)
+	defer cancel()
+
+	err := task.run(ctx)
+	switch {
+	case errors.Is(err, ErrResendThrottled):
+		logger.Logger.Warn("Mail task throttled", logger.String("task", task.name))
+	case err != nil:
+		logger.Logger.Error("Mail task failed", logger.String("task", task.name), logger.Error(err))
+	}
+}
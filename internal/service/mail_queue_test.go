@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMailQueueStopDrainsPendingTasks(t *testing.T) {
+	q := NewMailQueue(1, 10, time.Second)
+	release := make(chan struct{})
+	var done atomic.Int32
+
+	for i := 0; i < 5; i++ {
+		if err := q.Enqueue("test", func(ctx context.Context) error {
+			<-release
+			done.Add(1)
+			return nil
+		}); err != nil {
+			t.Fatalf("enqueue %d: %v", i, err)
+		}
+	}
+	close(release)
+
+	if err := q.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := done.Load(); got != 5 {
+		t.Errorf("%d tasks ran before Stop returned, want 5", got)
+	}
+	if err := q.Enqueue("late", func(ctx context.Context) error { return nil }); !errors.Is(err, ErrMailQueueFull) {
+		t.Errorf("enqueue after Stop: got %v, want ErrMailQueueFull", err)
+	}
+}
+
+func TestMailQueueRejectsWhenFull(t *testing.T) {
+	q := NewMailQueue(1, 1, time.Second)
+	release := make(chan struct{})
+	started := make(chan struct{})
+	block := func(ctx context.Context) error {
+		<-release
+		return nil
+	}
+
+	// Worker ilk işi alıp bekler, ikinci iş kuyruğu doldurur
+	q.Enqueue("running", func(ctx context.Context) error {
+		close(started)
+		return block(ctx)
+	})
+	<-started
+	if err := q.Enqueue("queued", block); err != nil {
+		t.Fatalf("second task: %v", err)
+	}
+	if err := q.Enqueue("overflow", block); !errors.Is(err, ErrMailQueueFull) {
+		t.Errorf("third task: got %v, want ErrMailQueueFull", err)
+	}
+
+	close(release)
+	q.Stop(context.Background())
+}
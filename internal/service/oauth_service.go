@@ -0,0 +1,547 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"elk-stack-user/internal/apperror"
+	"elk-stack-user/internal/logger"
+	"elk-stack-user/internal/model"
+	"elk-stack-user/internal/oidc"
+	"elk-stack-user/internal/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalidOAuthClient ve ErrInvalidRedirectURI authorize isteğinde kullanıcı
+	// redirect edilmeden gösterilir, aksi halde open redirect olur
+	ErrInvalidOAuthClient        = errors.New("unknown or revoked client")
+	ErrInvalidRedirectURI        = errors.New("redirect_uri is not registered for this client")
+	ErrOAuthClientNotFound       = apperror.NotFound("oauth client not found")
+	ErrInvalidOAuthClientRequest = apperror.Validation("invalid oauth client request")
+)
+
+// OAuthError RFC 6749 hata kodu ve açıklaması
+type OAuthError struct {
+	Code        string
+	Description string
+}
+
+func (e *OAuthError) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+func oauthError(code, description string) *OAuthError {
+	return &OAuthError{Code: code, Description: description}
+}
+
+// Provider'ın desteklediği standart OIDC scope'ları
+const (
+	ScopeOpenID  = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+)
+
+// ClientCredentials token endpoint'lerinde client kimlik doğrulaması (Basic veya form)
+type ClientCredentials struct {
+	ClientID     string
+	ClientSecret string
+}
+
+type TokenRequest struct {
+	GrantType    string
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+	Scope        string
+	Client       ClientCredentials
+}
+
+// AuthorizeResult başarılı login'de RedirectURL, MFA gerekiyorsa MFAToken dolu döner
+type AuthorizeResult struct {
+	RedirectURL string
+	MFAToken    string
+}
+
+// OAuthService OAuth2 authorization server / OpenID Connect provider.
+// Login adımı userService.VerifyLogin'i kullanır, böylece ban sistemi ve MFA aynen uygulanır ama session açılmaz.
+type OAuthService interface {
+	RegisterClient(ctx context.Context, adminID uint, req *model.CreateOAuthClientRequest) (*model.OAuthClientResponse, error)
+	ListClients(ctx context.Context) ([]*model.OAuthClientResponse, error)
+	RevokeClient(ctx context.Context, adminID, id uint) error
+
+	ValidateAuthorizeRequest(ctx context.Context, req *model.AuthorizeRequest) error
+	Authorize(ctx context.Context, req *model.AuthorizeRequest, login *model.LoginRequest, ipAddress, userAgent string) (*AuthorizeResult, error)
+	AuthorizeMFA(ctx context.Context, req *model.AuthorizeRequest, mfa *model.MFALoginRequest, ipAddress, userAgent string) (*AuthorizeResult, error)
+	Token(ctx context.Context, req *TokenRequest) (*model.TokenResponse, error)
+	Introspect(ctx context.Context, client ClientCredentials, token string) (*model.IntrospectionResponse, error)
+	Revoke(ctx context.Context, client ClientCredentials, token string) error
+	UserInfo(ctx context.Context, accessToken string) (map[string]interface{}, error)
+
+	Discovery() map[string]interface{}
+	JWKS() map[string]interface{}
+}
+
+type oauthService struct {
+	repo        repository.OAuthRepository
+	userService UserService
+	signer      *oidc.Signer
+	config      *Config
+}
+
+func NewOAuthService(repo repository.OAuthRepository, userService UserService, config *Config) OAuthService {
+	return &oauthService{
+		repo:        repo,
+		userService: userService,
+		signer:      config.OIDCSigner,
+		config:      config,
+	}
+}
+
+// Client registration
+
+func (s *oauthService) RegisterClient(ctx context.Context, adminID uint, req *model.CreateOAuthClientRequest) (*model.OAuthClientResponse, error) {
+	for _, grant := range req.GrantTypes {
+		if grant == model.GrantTypeAuthorizationCode && len(req.RedirectURIs) == 0 {
+			return nil, fmt.Errorf("%w: redirect_uris are required for authorization_code", ErrInvalidOAuthClientRequest)
+		}
+		if grant == model.GrantTypeClientCredentials && req.Public {
+			return nil, fmt.Errorf("%w: public clients cannot use client_credentials", ErrInvalidOAuthClientRequest)
+		}
+	}
+	for _, uri := range req.RedirectURIs {
+		parsed, err := url.Parse(uri)
+		if err != nil || !parsed.IsAbs() || parsed.Fragment != "" || strings.ContainsAny(uri, " ") {
+			return nil, fmt.Errorf("%w: invalid redirect uri %q", ErrInvalidOAuthClientRequest, uri)
+		}
+	}
+	for _, scope := range req.Scopes {
+		if scope == "" || strings.ContainsAny(scope, " \"\\") {
+			return nil, fmt.Errorf("%w: invalid scope %q", ErrInvalidOAuthClientRequest, scope)
+		}
+	}
+
+	client := &model.OAuthClient{
+		ClientID:     generateRandomString(32),
+		Name:         req.Name,
+		RedirectURIs: strings.Join(req.RedirectURIs, " "),
+		GrantTypes:   strings.Join(req.GrantTypes, " "),
+		Scopes:       strings.Join(req.Scopes, " "),
+		Public:       req.Public,
+		CreatedBy:    &adminID,
+	}
+	var secret string
+	if !req.Public {
+		secret = generateRandomString(64)
+		client.SecretHash = hashToken(secret)
+	}
+
+	if err := s.repo.CreateClient(ctx, client); err != nil {
+		return nil, err
+	}
+
+	logger.Logger.Warn("OAuth client registered by admin",
+		logger.SecurityEvent("oauth_client_registered"),
+		logger.Uint("admin_id", adminID),
+		logger.String("client_id", client.ClientID),
+		logger.String("name", client.Name),
+		logger.String("grant_types", client.GrantTypes),
+	)
+
+	response := toOAuthClientResponse(client)
+	response.ClientSecret = secret
+	return response, nil
+}
+
+func (s *oauthService) ListClients(ctx context.Context) ([]*model.OAuthClientResponse, error) {
+	clients, err := s.repo.ListClients(ctx)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]*model.OAuthClientResponse, len(clients))
+	for i, client := range clients {
+		responses[i] = toOAuthClientResponse(client)
+	}
+	return responses, nil
+}
+
+func (s *oauthService) RevokeClient(ctx context.Context, adminID, id uint) error {
+	if err := s.repo.RevokeClient(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrOAuthClientNotFound
+		}
+		return err
+	}
+
+	logger.Logger.Warn("OAuth client revoked by admin",
+		logger.SecurityEvent("oauth_client_revoked"),
+		logger.Uint("admin_id", adminID),
+		logger.Uint("oauth_client_id", id),
+	)
+	return nil
+}
+
+// Authorization code flow
+
+func (s *oauthService) ValidateAuthorizeRequest(ctx context.Context, req *model.AuthorizeRequest) error {
+	client, err := s.repo.GetActiveClient(ctx, req.ClientID)
+	if err != nil {
+		return ErrInvalidOAuthClient
+	}
+	if !contains(client.RedirectURIList(), req.RedirectURI) {
+		return ErrInvalidRedirectURI
+	}
+
+	// Buradan sonraki hatalar client'a redirect ile bildirilebilir
+	if req.ResponseType != "code" {
+		return oauthError("unsupported_response_type", "only response_type=code is supported")
+	}
+	if !contains(client.GrantTypeList(), model.GrantTypeAuthorizationCode) {
+		return oauthError("unauthorized_client", "client is not allowed to use the authorization code grant")
+	}
+	if req.CodeChallenge == "" || req.CodeChallengeMethod != "S256" {
+		return oauthError("invalid_request", "PKCE with code_challenge_method=S256 is required")
+	}
+	if !scopesAllowed(client, req.Scope) {
+		return oauthError("invalid_scope", "requested scope is not allowed for this client")
+	}
+	return nil
+}
+
+func (s *oauthService) Authorize(ctx context.Context, req *model.AuthorizeRequest, login *model.LoginRequest, ipAddress, userAgent string) (*AuthorizeResult, error) {
+	if err := s.ValidateAuthorizeRequest(ctx, req); err != nil {
+		return nil, err
+	}
+
+	// Sadece kimlik doğrulanır; authorize akışı kullanıcıya session değil authorization code verir
+	response, err := s.userService.VerifyLogin(ctx, login, ipAddress, userAgent)
+	if err != nil {
+		return nil, err
+	}
+	if response.MFARequired {
+		return &AuthorizeResult{MFAToken: response.MFAToken}, nil
+	}
+	return s.issueAuthorizationCode(ctx, req, response.User.ID)
+}
+
+func (s *oauthService) AuthorizeMFA(ctx context.Context, req *model.AuthorizeRequest, mfa *model.MFALoginRequest, ipAddress, userAgent string) (*AuthorizeResult, error) {
+	if err := s.ValidateAuthorizeRequest(ctx, req); err != nil {
+		return nil, err
+	}
+
+	response, err := s.userService.VerifyMFALogin(ctx, mfa, ipAddress, userAgent)
+	if err != nil {
+		return nil, err
+	}
+	return s.issueAuthorizationCode(ctx, req, response.User.ID)
+}
+
+func (s *oauthService) issueAuthorizationCode(ctx context.Context, req *model.AuthorizeRequest, userID uint) (*AuthorizeResult, error) {
+	code := generateRandomString(64)
+	now := time.Now()
+	record := &model.OAuthAuthorizationCode{
+		CodeHash:            hashToken(code),
+		ClientID:            req.ClientID,
+		UserID:              userID,
+		RedirectURI:         req.RedirectURI,
+		Scope:               req.Scope,
+		Nonce:               req.Nonce,
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+		AuthTime:            now,
+		ExpiresAt:           now.Add(s.config.OIDCAuthCodeTTL),
+	}
+	if err := s.repo.CreateAuthorizationCode(ctx, record); err != nil {
+		return nil, err
+	}
+
+	redirect, err := url.Parse(req.RedirectURI)
+	if err != nil {
+		return nil, err
+	}
+	query := redirect.Query()
+	query.Set("code", code)
+	if req.State != "" {
+		query.Set("state", req.State)
+	}
+	redirect.RawQuery = query.Encode()
+	return &AuthorizeResult{RedirectURL: redirect.String()}, nil
+}
+
+// Token endpoint
+
+func (s *oauthService) Token(ctx context.Context, req *TokenRequest) (*model.TokenResponse, error) {
+	client, err := s.authenticateClient(ctx, req.Client)
+	if err != nil {
+		return nil, err
+	}
+	if !contains(client.GrantTypeList(), req.GrantType) {
+		return nil, oauthError("unauthorized_client", "client is not allowed to use this grant type")
+	}
+
+	switch req.GrantType {
+	case model.GrantTypeAuthorizationCode:
+		return s.exchangeAuthorizationCode(ctx, client, req)
+	case model.GrantTypeClientCredentials:
+		return s.clientCredentials(ctx, client, req)
+	default:
+		return nil, oauthError("unsupported_grant_type", "grant_type must be authorization_code or client_credentials")
+	}
+}
+
+func (s *oauthService) exchangeAuthorizationCode(ctx context.Context, client *model.OAuthClient, req *TokenRequest) (*model.TokenResponse, error) {
+	code, err := s.repo.ConsumeAuthorizationCode(ctx, hashToken(req.Code))
+	if err != nil {
+		return nil, oauthError("invalid_grant", "authorization code is invalid, expired or already used")
+	}
+	if code.ClientID != client.ClientID || code.RedirectURI != req.RedirectURI {
+		return nil, oauthError("invalid_grant", "authorization code was issued to another client or redirect_uri")
+	}
+	if !oidc.VerifyPKCE(req.CodeVerifier, code.CodeChallenge, code.CodeChallengeMethod) {
+		return nil, oauthError("invalid_grant", "code_verifier does not match code_challenge")
+	}
+
+	user, err := s.userService.GetUserByID(ctx, code.UserID)
+	if err != nil || !user.IsActive {
+		return nil, oauthError("invalid_grant", "user is no longer available")
+	}
+
+	response, err := s.issueAccessToken(ctx, client.ClientID, &user.ID, code.Scope)
+	if err != nil {
+		return nil, err
+	}
+
+	if hasScope(code.Scope, ScopeOpenID) {
+		now := time.Now()
+		claims := userClaims(user, code.Scope)
+		claims["iss"] = s.config.OIDCIssuer
+		claims["aud"] = client.ClientID
+		claims["iat"] = now.Unix()
+		claims["exp"] = now.Add(s.config.OIDCIDTokenTTL).Unix()
+		claims["auth_time"] = code.AuthTime.Unix()
+		if code.Nonce != "" {
+			claims["nonce"] = code.Nonce
+		}
+		if response.IDToken, err = s.signer.Sign(claims); err != nil {
+			return nil, err
+		}
+	}
+
+	logger.Logger.Info("OAuth tokens issued",
+		logger.String("client_id", client.ClientID),
+		logger.UserID(user.ID),
+		logger.String("grant_type", model.GrantTypeAuthorizationCode),
+		logger.String("scope", code.Scope),
+	)
+	return response, nil
+}
+
+func (s *oauthService) clientCredentials(ctx context.Context, client *model.OAuthClient, req *TokenRequest) (*model.TokenResponse, error) {
+	if client.Public {
+		return nil, oauthError("unauthorized_client", "public clients cannot use client_credentials")
+	}
+	scope := req.Scope
+	if scope == "" {
+		scope = client.Scopes
+	}
+	if hasScope(scope, ScopeOpenID) || !scopesAllowed(client, scope) {
+		return nil, oauthError("invalid_scope", "requested scope is not allowed for this client")
+	}
+
+	response, err := s.issueAccessToken(ctx, client.ClientID, nil, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Logger.Info("OAuth tokens issued",
+		logger.String("client_id", client.ClientID),
+		logger.String("grant_type", model.GrantTypeClientCredentials),
+		logger.String("scope", scope),
+	)
+	return response, nil
+}
+
+func (s *oauthService) issueAccessToken(ctx context.Context, clientID string, userID *uint, scope string) (*model.TokenResponse, error) {
+	token := generateRandomString(64)
+	record := &model.OAuthAccessToken{
+		TokenHash: hashToken(token),
+		ClientID:  clientID,
+		UserID:    userID,
+		Scope:     scope,
+		ExpiresAt: time.Now().Add(s.config.OIDCAccessTokenTTL),
+	}
+	if err := s.repo.CreateAccessToken(ctx, record); err != nil {
+		return nil, err
+	}
+	return &model.TokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(s.config.OIDCAccessTokenTTL / time.Second),
+		Scope:       scope,
+	}, nil
+}
+
+// authenticateClient confidential client'larda secret'ı, public client'larda secret olmamasını kontrol eder
+func (s *oauthService) authenticateClient(ctx context.Context, credentials ClientCredentials) (*model.OAuthClient, error) {
+	client, err := s.repo.GetActiveClient(ctx, credentials.ClientID)
+	if err != nil {
+		return nil, oauthError("invalid_client", "client authentication failed")
+	}
+	if client.Public {
+		if credentials.ClientSecret != "" {
+			return nil, oauthError("invalid_client", "client authentication failed")
+		}
+		return client, nil
+	}
+	if subtle.ConstantTimeCompare([]byte(hashToken(credentials.ClientSecret)), []byte(client.SecretHash)) != 1 {
+		return nil, oauthError("invalid_client", "client authentication failed")
+	}
+	return client, nil
+}
+
+// Introspection, revocation, userinfo
+
+func (s *oauthService) Introspect(ctx context.Context, credentials ClientCredentials, token string) (*model.IntrospectionResponse, error) {
+	client, err := s.authenticateClient(ctx, credentials)
+	if err != nil {
+		return nil, err
+	}
+	if client.Public {
+		return nil, oauthError("invalid_client", "public clients cannot introspect tokens")
+	}
+
+	record, err := s.repo.GetActiveAccessToken(ctx, hashToken(token))
+	if err != nil {
+		return &model.IntrospectionResponse{Active: false}, nil
+	}
+
+	response := &model.IntrospectionResponse{
+		Active:    true,
+		Scope:     record.Scope,
+		ClientID:  record.ClientID,
+		TokenType: "Bearer",
+		ExpiresAt: record.ExpiresAt.Unix(),
+		IssuedAt:  record.CreatedAt.Unix(),
+		Issuer:    s.config.OIDCIssuer,
+	}
+	if record.UserID != nil {
+		response.Subject = strconv.FormatUint(uint64(*record.UserID), 10)
+	}
+	return response, nil
+}
+
+// Revoke RFC 7009: token bulunamasa da başarılı döner
+func (s *oauthService) Revoke(ctx context.Context, credentials ClientCredentials, token string) error {
+	client, err := s.authenticateClient(ctx, credentials)
+	if err != nil {
+		return err
+	}
+	return s.repo.RevokeAccessToken(ctx, hashToken(token), client.ClientID)
+}
+
+func (s *oauthService) UserInfo(ctx context.Context, accessToken string) (map[string]interface{}, error) {
+	record, err := s.repo.GetActiveAccessToken(ctx, hashToken(accessToken))
+	if err != nil || record.UserID == nil || !hasScope(record.Scope, ScopeOpenID) {
+		return nil, ErrInvalidToken
+	}
+
+	user, err := s.userService.GetUserByID(ctx, *record.UserID)
+	if err != nil || !user.IsActive {
+		return nil, ErrInvalidToken
+	}
+	return userClaims(user, record.Scope), nil
+}
+
+// Metadata
+
+func (s *oauthService) Discovery() map[string]interface{} {
+	issuer := s.config.OIDCIssuer
+	return map[string]interface{}{
+		"issuer":                                issuer,
+		"authorization_endpoint":                issuer + "/oauth/authorize",
+		"token_endpoint":                        issuer + "/oauth/token",
+		"userinfo_endpoint":                     issuer + "/oauth/userinfo",
+		"jwks_uri":                              issuer + "/oauth/jwks.json",
+		"introspection_endpoint":                issuer + "/oauth/introspect",
+		"revocation_endpoint":                   issuer + "/oauth/revoke",
+		"response_types_supported":              []string{"code"},
+		"grant_types_supported":                 []string{model.GrantTypeAuthorizationCode, model.GrantTypeClientCredentials},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"scopes_supported":                      []string{ScopeOpenID, ScopeProfile, ScopeEmail},
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post", "none"},
+		"code_challenge_methods_supported":      []string{"S256"},
+		"claims_supported": []string{
+			"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce",
+			"preferred_username", "name", "given_name", "family_name", "updated_at",
+			"email", "email_verified",
+		},
+	}
+}
+
+func (s *oauthService) JWKS() map[string]interface{} {
+	return s.signer.JWKS()
+}
+
+// userClaims scope'a göre model.User'dan standart OIDC claim'lerini üretir
+func userClaims(user *model.UserResponse, scope string) map[string]interface{} {
+	claims := map[string]interface{}{
+		"sub": strconv.FormatUint(uint64(user.ID), 10),
+	}
+	if hasScope(scope, ScopeProfile) {
+		claims["preferred_username"] = user.Username
+		claims["given_name"] = user.FirstName
+		claims["family_name"] = user.LastName
+		claims["name"] = strings.TrimSpace(user.FirstName + " " + user.LastName)
+		claims["updated_at"] = user.UpdatedAt.Unix()
+	}
+	if hasScope(scope, ScopeEmail) {
+		claims["email"] = user.Email
+		claims["email_verified"] = user.EmailVerifiedAt != nil
+	}
+	return claims
+}
+
+func toOAuthClientResponse(client *model.OAuthClient) *model.OAuthClientResponse {
+	return &model.OAuthClientResponse{
+		ID:           client.ID,
+		ClientID:     client.ClientID,
+		Name:         client.Name,
+		RedirectURIs: client.RedirectURIList(),
+		GrantTypes:   client.GrantTypeList(),
+		Scopes:       client.ScopeList(),
+		Public:       client.Public,
+		CreatedAt:    client.CreatedAt,
+		RevokedAt:    client.RevokedAt,
+	}
+}
+
+// scopesAllowed istenen her scope client'ın kayıtlı scope'ları arasında olmalı
+func scopesAllowed(client *model.OAuthClient, scope string) bool {
+	allowed := client.ScopeList()
+	for _, s := range strings.Fields(scope) {
+		if !contains(allowed, s) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasScope(scope, want string) bool {
+	return contains(strings.Fields(scope), want)
+}
+
+func contains(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"elk-stack-user/internal/apperror"
+)
+
+var ErrWeakPassword = apperror.Validation("password does not meet policy")
+
+// PasswordPolicy signup, şifre değiştirme ve sıfırlamada uygulanan kurallar
+type PasswordPolicy struct {
+	MinLength int
+	// MaxBytes bcrypt 72 byte'tan sonrasını sessizce yok saydığı için üst sınır
+	MaxBytes      int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	// DisallowUserInfo şifrenin kullanıcı adı veya e-posta içermesini engeller
+	DisallowUserInfo bool
+	// Breached nil değilse sızdırılmış şifre listesi kontrolü yapılır
+	Breached *BreachedPasswordList
+}
+
+// PolicyViolation ihlal edilen tek bir kural
+type PolicyViolation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// PasswordPolicyError ihlal edilen tüm kuralları taşır, errors.Is(err, ErrWeakPassword) true döner
+type PasswordPolicyError struct {
+	Violations []PolicyViolation
+}
+
+func (e *PasswordPolicyError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.Message
+	}
+	return ErrWeakPassword.Error() + ": " + strings.Join(messages, "; ")
+}
+
+func (e *PasswordPolicyError) Unwrap() error {
+	return ErrWeakPassword
+}
+
+// Details ihlal edilen kuralları yanıta ekler
+func (e *PasswordPolicyError) Details() map[string]interface{} {
+	return map[string]interface{}{"violations": e.Violations}
+}
+
+func NewPasswordPolicy() *PasswordPolicy {
+	return &PasswordPolicy{
+		MinLength:        getEnvInt("PASSWORD_MIN_LENGTH", 8),
+		MaxBytes:         getEnvInt("PASSWORD_MAX_BYTES", 72),
+		RequireUpper:     getEnvBool("PASSWORD_REQUIRE_UPPER", false),
+		RequireLower:     getEnvBool("PASSWORD_REQUIRE_LOWER", false),
+		RequireDigit:     getEnvBool("PASSWORD_REQUIRE_DIGIT", false),
+		RequireSymbol:    getEnvBool("PASSWORD_REQUIRE_SYMBOL", false),
+		DisallowUserInfo: getEnvBool("PASSWORD_DISALLOW_USER_INFO", true),
+	}
+}
+
+// Validate şifreyi tüm kurallara karşı kontrol eder ve ihlallerin hepsini birlikte döner
+func (p *PasswordPolicy) Validate(password, username, email string) error {
+	var violations []PolicyViolation
+	add := func(rule, message string) {
+		violations = append(violations, PolicyViolation{Rule: rule, Message: message})
+	}
+
+	if len([]rune(password)) < p.MinLength {
+		add("min_length", "password must be at least "+strconv.Itoa(p.MinLength)+" characters long")
+	}
+	if p.MaxBytes > 0 && len(password) > p.MaxBytes {
+		add("max_length", "password must be at most "+strconv.Itoa(p.MaxBytes)+" bytes long")
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if p.RequireUpper && !hasUpper {
+		add("uppercase", "password must contain an uppercase letter")
+	}
+	if p.RequireLower && !hasLower {
+		add("lowercase", "password must contain a lowercase letter")
+	}
+	if p.RequireDigit && !hasDigit {
+		add("digit", "password must contain a digit")
+	}
+	if p.RequireSymbol && !hasSymbol {
+		add("symbol", "password must contain a symbol")
+	}
+
+	if p.DisallowUserInfo {
+		lower := strings.ToLower(password)
+		if containsUserInfo(lower, username) {
+			add("no_username", "password must not contain the username")
+		}
+		localPart, _, _ := strings.Cut(email, "@")
+		if containsUserInfo(lower, localPart) {
+			add("no_email", "password must not contain the email address")
+		}
+	}
+
+	if p.Breached != nil && p.Breached.Contains(password) {
+		add("breached", "password has appeared in a data breach and cannot be used")
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// containsUserInfo çok kısa değerler (ör. "al") yanlış pozitif üretmesin diye 3 karakterden kısa değerleri atlar
+func containsUserInfo(lowerPassword, value string) bool {
+	value = strings.ToLower(strings.TrimSpace(value))
+	return len([]rune(value)) >= 3 && strings.Contains(lowerPassword, value)
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"elk-stack-user/internal/apperror"
+)
+
+// ErrMailRateLimited e-posta gönderen bir endpoint aynı adres veya IP için çok sık çağrıldı
+var ErrMailRateLimited = apperror.RateLimited("too many email requests, try again later")
+
+// RateLimitError sınır aşıldığında pencerenin ne zaman açılacağını taşır,
+// errors.Is(err, ErrMailRateLimited) ile yakalanır
+type RateLimitError struct {
+	RetryAt time.Time
+}
+
+func (e *RateLimitError) Error() string {
+	return ErrMailRateLimited.Error()
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return ErrMailRateLimited
+}
+
+// RetryAfter Retry-After header'ı için kalan süreyi yukarı yuvarlanmış saniye olarak döner
+func (e *RateLimitError) RetryAfter(now time.Time) int64 {
+	remaining := e.RetryAt.Sub(now)
+	if remaining <= 0 {
+		return 1
+	}
+	return int64((remaining + time.Second - 1) / time.Second)
+}
+
+// rateLimiter anahtar başına sabit bir pencerede en fazla limit isteğe izin verir.
+// Sayaçlar instance'ın belleğinde tutulur; birden fazla instance'ta sınır instance başınadır.
+type rateLimiter struct {
+	limit  int
+	window time.Duration
+
+	mu        sync.Mutex
+	windows   map[string]*rateWindow
+	lastSweep time.Time
+}
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// newRateLimiter limit <= 0 ise her isteğe izin veren bir limiter döner
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{limit: limit, window: window, windows: make(map[string]*rateWindow)}
+}
+
+// Allow isteği key için sayar. Sınır aşıldıysa ok=false ve pencerenin açılacağı zamanı döner.
+func (l *rateLimiter) Allow(key string, now time.Time) (ok bool, retryAt time.Time) {
+	if l.limit <= 0 {
+		return true, time.Time{}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Süresi dolmuş pencereler ara ara temizlenir, map sınırsız büyümesin
+	if now.Sub(l.lastSweep) >= l.window {
+		for k, w := range l.windows {
+			if now.Sub(w.start) >= l.window {
+				delete(l.windows, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	w, found := l.windows[key]
+	if !found || now.Sub(w.start) >= l.window {
+		l.windows[key] = &rateWindow{start: now, count: 1}
+		return true, time.Time{}
+	}
+	if w.count >= l.limit {
+		return false, w.start.Add(l.window)
+	}
+	w.count++
+	return true, time.Time{}
+}
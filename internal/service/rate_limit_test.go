@@ -0,0 +1,54 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterAllow(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := newRateLimiter(2, time.Minute)
+
+	tests := []struct {
+		key   string
+		at    time.Duration
+		allow bool
+	}{
+		{"a", 0, true},
+		{"a", 10 * time.Second, true},
+		{"a", 20 * time.Second, false},
+		{"b", 20 * time.Second, true},
+		{"a", 59 * time.Second, false},
+		{"a", time.Minute, true},
+	}
+	for _, tt := range tests {
+		ok, retryAt := limiter.Allow(tt.key, start.Add(tt.at))
+		if ok != tt.allow {
+			t.Fatalf("Allow(%q) at +%s = %v, want %v", tt.key, tt.at, ok, tt.allow)
+		}
+		if !ok && !retryAt.Equal(start.Add(time.Minute)) {
+			t.Errorf("retryAt = %s, want end of the first window", retryAt)
+		}
+	}
+}
+
+func TestRateLimiterDisabled(t *testing.T) {
+	limiter := newRateLimiter(0, time.Minute)
+	for i := 0; i < 100; i++ {
+		if ok, _ := limiter.Allow("a", time.Now()); !ok {
+			t.Fatal("limit 0 should not limit")
+		}
+	}
+}
+
+func TestRateLimitErrorIsRateLimited(t *testing.T) {
+	now := time.Now()
+	err := error(&RateLimitError{RetryAt: now.Add(1500 * time.Millisecond)})
+	if !errors.Is(err, ErrMailRateLimited) {
+		t.Fatal("RateLimitError should match ErrMailRateLimited")
+	}
+	if got := err.(*RateLimitError).RetryAfter(now); got != 2 {
+		t.Errorf("RetryAfter = %d, want 2", got)
+	}
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"elk-stack-user/internal/apperror"
+	"elk-stack-user/internal/logger"
+	"elk-stack-user/internal/model"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrDeletedUserNotFound = apperror.NotFound("deleted user not found")
+	ErrRestoreConflict     = apperror.Conflict("username or email is already used by another account")
+)
+
+func (s *userService) ListDeletedUsers(ctx context.Context, page, pageSize int) ([]*model.DeletedUserResponse, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > s.config.MaxPageSize {
+		pageSize = 20
+	}
+
+	users, total, err := s.userRepo.ListDeleted(ctx, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]*model.DeletedUserResponse, len(users))
+	for i, user := range users {
+		responses[i] = &model.DeletedUserResponse{
+			UserResponse: s.toUserResponse(user),
+			DeletedAt:    user.DeletedAt.Time,
+		}
+	}
+	return responses, total, nil
+}
+
+func (s *userService) RestoreUser(ctx context.Context, adminID, id uint) (*model.UserResponse, error) {
+	user, err := s.userRepo.GetDeletedByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDeletedUserNotFound
+		}
+		return nil, err
+	}
+
+	if err := s.userRepo.Restore(ctx, id); err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, ErrDeletedUserNotFound
+		case errors.Is(err, apperror.ErrConflict):
+			// Silindikten sonra aynı username veya email ile yeni bir hesap açılmış
+			return nil, fmt.Errorf("%w: %v", ErrRestoreConflict, err)
+		}
+		return nil, err
+	}
+
+	logger.Logger.Warn("User restored by admin",
+		logger.SecurityEvent("admin_user_restored"),
+		logger.Uint("admin_id", adminID),
+		logger.UserID(user.ID),
+		logger.Username(user.Username),
+	)
+
+	restored, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.toUserResponse(restored), nil
+}
+
+func (s *userService) PurgeUser(ctx context.Context, adminID, id uint) error {
+	user, err := s.userRepo.GetDeletedByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrDeletedUserNotFound
+		}
+		return err
+	}
+
+	purged, err := s.userRepo.Purge(ctx, id)
+	if err != nil {
+		return err
+	}
+	if purged == 0 {
+		// Arada restore edilmiş
+		return ErrDeletedUserNotFound
+	}
+
+	logger.Logger.Warn("User purged by admin",
+		logger.SecurityEvent("admin_user_purged"),
+		logger.Uint("admin_id", adminID),
+		logger.UserID(user.ID),
+		logger.Username(user.Username),
+		logger.Time("deleted_at", user.DeletedAt.Time),
+	)
+	return nil
+}
@@ -3,11 +3,25 @@ package service
 import (
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
 	"encoding/hex"
 	"errors"
+	"fmt"
+	"hash/fnv"
+	"html"
+	"net/url"
+	"strings"
+	"sync"
+	"unicode/utf8"
+	"elk-stack-user/internal/apperror"
+	"elk-stack-user/internal/logger"
+	"elk-stack-user/internal/mailer"
 	"elk-stack-user/internal/model"
+	"elk-stack-user/internal/normalize"
 	"elk-stack-user/internal/repository"
-	"golang.org/x/crypto/bcrypt"
+	"elk-stack-user/internal/totp"
+	"go.uber.org/zap"
 	"time"
 )
 
@@ -15,51 +29,135 @@ type UserService interface {
 	CreateUser(ctx context.Context, req *model.CreateUserRequest) (*model.UserResponse, error)
 	GetUserByID(ctx context.Context, id uint) (*model.UserResponse, error)
 	GetUserByEmail(ctx context.Context, email string) (*model.UserResponse, error)
-	GetAllUsers(ctx context.Context, page, pageSize int) ([]*model.UserResponse, int64, error)
-	UpdateUser(ctx context.Context, id uint, req *model.UpdateUserRequest) (*model.UserResponse, error)
-	DeleteUser(ctx context.Context, id uint) error
+	ListUsers(ctx context.Context, filter *model.UserFilter, req *model.UserListRequest) (*model.UserPage, error)
+	SearchUsers(ctx context.Context, query string, limit int) ([]*model.UserSearchHit, error)
+	// ReplaceUser, PatchUser ve DeleteUser precondition nil değilse sadece kullanıcının güncel versiyonu eşleşirse yazar
+	ReplaceUser(ctx context.Context, id uint, req *model.ReplaceUserRequest, precondition *model.Precondition) (*model.UserResponse, error)
+	PatchUser(ctx context.Context, id uint, patch UserPatch, precondition *model.Precondition) (*model.UserResponse, error)
+	DeleteUser(ctx context.Context, id uint, precondition *model.Precondition) error
+	// Admin: soft-delete edilmiş kullanıcılar
+	ListDeletedUsers(ctx context.Context, page, pageSize int) ([]*model.DeletedUserResponse, int64, error)
+	RestoreUser(ctx context.Context, adminID, id uint) (*model.UserResponse, error)
+	PurgeUser(ctx context.Context, adminID, id uint) error
 	// Login methods
 	Login(ctx context.Context, req *model.LoginRequest, ipAddress, userAgent string) (*model.LoginResponse, error)
+	// VerifyLogin Login'in ban ve deneme kaydı dahil tüm kontrollerini yapar ama session açmaz
+	// (OAuth authorize gibi kendi token'ını veren akışlar için)
+	VerifyLogin(ctx context.Context, req *model.LoginRequest, ipAddress, userAgent string) (*model.LoginResponse, error)
 	IsUserBanned(ctx context.Context, username, ipAddress string) (*model.BanRecord, error)
+	// Session methods
+	Authenticate(ctx context.Context, token string) (*model.User, *model.Session, error)
+	ChangePassword(ctx context.Context, userID, sessionID uint, req *model.ChangePasswordRequest, ipAddress, userAgent string) error
+	// Password reset methods
+	ForgotPassword(ctx context.Context, email, ipAddress string) error
+	ResetPassword(ctx context.Context, req *model.ResetPasswordRequest) (*model.User, error)
+	// Email verification methods
+	VerifyEmail(ctx context.Context, token string) (*model.User, error)
+	ResendVerificationEmail(ctx context.Context, email, ipAddress string) error
+	// MFA methods
+	CompleteMFALogin(ctx context.Context, req *model.MFALoginRequest, ipAddress, userAgent string) (*model.LoginResponse, error)
+	// VerifyMFALogin CompleteMFALogin'in session açmayan karşılığı
+	VerifyMFALogin(ctx context.Context, req *model.MFALoginRequest, ipAddress, userAgent string) (*model.LoginResponse, error)
+	EnrollTOTP(ctx context.Context, userID uint) (*model.TOTPEnrollmentResponse, error)
+	TOTPQRCode(ctx context.Context, userID uint) ([]byte, error)
+	ConfirmTOTP(ctx context.Context, userID uint, code string) ([]string, error)
+	DisableTOTP(ctx context.Context, userID uint, password string) error
 }
 
+var (
+	ErrAccountBanned      = apperror.Banned("account temporarily banned due to multiple failed login attempts")
+	ErrInvalidToken       = apperror.Unauthorized("invalid or expired token")
+	ErrIncorrectPassword  = apperror.Forbidden("current password is incorrect")
+	ErrInvalidResetToken  = apperror.Validation("invalid or expired reset token")
+	ErrInvalidVerifyToken = apperror.Validation("invalid or expired verification token")
+	ErrEmailNotVerified   = apperror.Forbidden("email address is not verified")
+	ErrResendThrottled    = apperror.RateLimited("verification email was sent recently")
+	ErrInvalidMFAToken    = apperror.Unauthorized("invalid or expired MFA token")
+	ErrInvalidMFACode     = apperror.Unauthorized("invalid authentication code")
+	ErrMFAAlreadyEnabled  = apperror.Conflict("two-factor authentication is already enabled")
+	ErrMFANotEnabled      = apperror.NotFound("two-factor authentication is not enabled")
+	ErrInvalidPageSize    = apperror.Validation("invalid page size")
+	ErrInvalidSearch      = apperror.Validation("invalid search query")
+	ErrNoPendingMFA       = apperror.NotFound("no pending two-factor enrollment")
+	ErrInvalidCredentials = apperror.Unauthorized("invalid credentials")
+	ErrAccountDeactivated = apperror.Forbidden("account is deactivated")
+	ErrEmailExists        = repository.ErrEmailExists
+	ErrUsernameExists     = repository.ErrUsernameExists
+)
+
+const recoveryCodeCount = 10
+
 type userService struct {
-	userRepo repository.UserRepository
+	userRepo         repository.UserRepository
+	sessionRepo      repository.SessionRepository
+	tokenRepo        repository.TokenRepository
+	recoveryCodeRepo repository.RecoveryCodeRepository
+	mailer           mailer.Mailer
+	config           *Config
+	cursors          *cursorCodec
+	// mailByEmail ve mailByIP e-posta gönderen public endpoint'lerin (forgot, resend) sınırları
+	mailByEmail *rateLimiter
+	mailByIP    *rateLimiter
+
+	// dummyHash hiç kullanıcı yokken bilinmeyen login'lerin doğrulama süresini eşitlemek için
+	dummyHash     string
+	dummyHashOnce sync.Once
 }
 
-func NewUserService(userRepo repository.UserRepository) UserService {
-	return &userService{userRepo: userRepo}
+func NewUserService(userRepo repository.UserRepository, sessionRepo repository.SessionRepository, tokenRepo repository.TokenRepository, recoveryCodeRepo repository.RecoveryCodeRepository, mail mailer.Mailer, config *Config) UserService {
+	return &userService{
+		userRepo:         userRepo,
+		sessionRepo:      sessionRepo,
+		tokenRepo:        tokenRepo,
+		recoveryCodeRepo: recoveryCodeRepo,
+		mailer:           mail,
+		config:           config,
+		cursors:          newCursorCodec(config.CursorSecret),
+		mailByEmail:      newRateLimiter(config.MailRateLimitPerEmail, config.MailRateLimitWindow),
+		mailByIP:         newRateLimiter(config.MailRateLimitPerIP, config.MailRateLimitWindow),
+	}
 }
 
 func (s *userService) CreateUser(ctx context.Context, req *model.CreateUserRequest) (*model.UserResponse, error) {
-	// Email ve username kontrolü
-	if _, err := s.userRepo.GetByEmail(ctx, req.Email); err == nil {
-		return nil, errors.New("email already exists")
+	username, err := normalizeUsername(req.Username)
+	if err != nil {
+		return nil, err
+	}
+	email, err := normalizeEmail(req.Email)
+	if err != nil {
+		return nil, err
 	}
-	if _, err := s.userRepo.GetByUsername(ctx, req.Username); err == nil {
-		return nil, errors.New("username already exists")
+
+	if err := s.config.PasswordPolicy.Validate(req.Password, username, email); err != nil {
+		return nil, err
 	}
 
 	// Password hash'leme
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashedPassword, err := s.config.PasswordHasher.Hash(req.Password)
 	if err != nil {
 		return nil, err
 	}
 
 	user := &model.User{
-		Username:  req.Username,
-		Email:     req.Email,
-		Password:  string(hashedPassword),
+		Username:  username,
+		Email:     email,
+		Password:  hashedPassword,
 		FirstName: req.FirstName,
 		LastName:  req.LastName,
 		Age:       req.Age,
 		IsActive:  true,
 	}
 
+	// Tekillik önceden sorgulanmaz: eşzamanlı kayıtlarda kontrol ile insert arasında yarış olur.
+	// Unique index ihlali repository'de ErrEmailExists/ErrUsernameExists'e çevrilir.
 	if err := s.userRepo.Create(ctx, user); err != nil {
 		return nil, err
 	}
 
+	// Doğrulama e-postası resend ile aynı kuyruktan gönderilir; yavaş bir SMTP kaydı bekletmez,
+	// kuyruğa alınamazsa kayıt yine de tamamlanır ve kullanıcı resend ile tekrar isteyebilir
+	s.queueVerificationEmail(ctx, user)
+
 	return s.toUserResponse(user), nil
 }
 
@@ -72,98 +170,270 @@ func (s *userService) GetUserByID(ctx context.Context, id uint) (*model.UserResp
 }
 
 func (s *userService) GetUserByEmail(ctx context.Context, email string) (*model.UserResponse, error) {
-	user, err := s.userRepo.GetByEmail(ctx, email)
+	user, err := s.userRepo.GetByEmail(ctx, normalize.Lookup(email))
 	if err != nil {
 		return nil, err
 	}
 	return s.toUserResponse(user), nil
 }
 
-func (s *userService) GetAllUsers(ctx context.Context, page, pageSize int) ([]*model.UserResponse, int64, error) {
-	if page < 1 {
-		page = 1
+func (s *userService) ListUsers(ctx context.Context, filter *model.UserFilter, req *model.UserListRequest) (*model.UserPage, error) {
+	if req.PageSize < 1 || req.PageSize > s.config.MaxPageSize {
+		return nil, fmt.Errorf("%w: page_size must be between 1 and %d", ErrInvalidPageSize, s.config.MaxPageSize)
 	}
-	if pageSize < 1 {
-		pageSize = 10
+	// Prefix'ler saklanan değerlerle aynı NFKC formuna getirilir, harf büyüklüğünü repository yok sayar
+	filter.UsernamePrefix = normalize.Text(filter.UsernamePrefix)
+	filter.EmailPrefix = normalize.Text(filter.EmailPrefix)
+
+	var cursor *model.UserCursor
+	if req.Cursor != "" {
+		var err error
+		if cursor, err = s.cursors.Decode(filter, req.Cursor); err != nil {
+			return nil, err
+		}
 	}
+	backward := cursor != nil && cursor.Backward
 
-	offset := (page - 1) * pageSize
-	users, err := s.userRepo.GetAll(ctx, pageSize, offset)
+	// Bir fazla satır okunarak sonraki sayfanın olup olmadığı anlaşılır
+	users, err := s.userRepo.List(ctx, filter, cursor, req.PageSize+1)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
+	}
+	hasMore := len(users) > req.PageSize
+	if hasMore {
+		// Geriye giderken fazla satır listenin başındadır
+		if backward {
+			users = users[1:]
+		} else {
+			users = users[:req.PageSize]
+		}
 	}
 
-	total, err := s.userRepo.Count(ctx)
+	page := &model.UserPage{Users: make([]*model.UserResponse, len(users))}
+	for i, user := range users {
+		page.Users[i] = s.toUserResponse(user)
+	}
+
+	if len(users) > 0 {
+		if backward || hasMore {
+			if page.NextCursor, err = s.cursors.Encode(filter, users[len(users)-1], false); err != nil {
+				return nil, err
+			}
+		}
+		if (backward && hasMore) || (!backward && cursor != nil) {
+			if page.PrevCursor, err = s.cursors.Encode(filter, users[0], true); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	switch req.Total {
+	case model.TotalExact:
+		total, err := s.userRepo.Count(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		page.Total = &total
+	case model.TotalEstimate:
+		// reltuples sadece tablonun tamamı için anlamlı, filtreli sorgularda tam sayım yapılır
+		total := int64(-1)
+		if !filter.HasConditions() {
+			if total, err = s.userRepo.EstimateCount(ctx); err != nil {
+				return nil, err
+			}
+			page.TotalEstimated = total >= 0
+		}
+		if total < 0 {
+			if total, err = s.userRepo.Count(ctx, filter); err != nil {
+				return nil, err
+			}
+		}
+		page.Total = &total
+	}
+
+	return page, nil
+}
+
+// minSearchLength trigram eşleşmesi için anlamlı en kısa sorgu
+const minSearchLength = 2
+
+func (s *userService) SearchUsers(ctx context.Context, query string, limit int) ([]*model.UserSearchHit, error) {
+	query = strings.TrimSpace(query)
+	if utf8.RuneCountInString(query) < minSearchLength {
+		return nil, fmt.Errorf("%w: q must be at least %d characters", ErrInvalidSearch, minSearchLength)
+	}
+	if limit < 1 || limit > s.config.MaxPageSize {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidPageSize, s.config.MaxPageSize)
+	}
+
+	results, err := s.userRepo.Search(ctx, query, limit)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 
-	userResponses := make([]*model.UserResponse, len(users))
-	for i, user := range users {
-		userResponses[i] = s.toUserResponse(user)
+	hits := make([]*model.UserSearchHit, len(results))
+	for i, result := range results {
+		hits[i] = &model.UserSearchHit{
+			User:       s.toUserResponse(result.User),
+			Score:      result.Rank + result.Similarity,
+			Rank:       result.Rank,
+			Similarity: result.Similarity,
+			Highlight:  renderHighlight(result.Highlight),
+		}
 	}
+	return hits, nil
+}
 
-	return userResponses, total, nil
+// renderHighlight kullanıcı verisini escape eder, ts_headline işaretlerini <mark> yapar
+func renderHighlight(highlight string) string {
+	escaped := html.EscapeString(highlight)
+	escaped = strings.ReplaceAll(escaped, repository.HighlightStart, "<mark>")
+	return strings.ReplaceAll(escaped, repository.HighlightStop, "</mark>")
 }
 
-func (s *userService) UpdateUser(ctx context.Context, id uint, req *model.UpdateUserRequest) (*model.UserResponse, error) {
+func (s *userService) ReplaceUser(ctx context.Context, id uint, req *model.ReplaceUserRequest, precondition *model.Precondition) (*model.UserResponse, error) {
 	user, err := s.userRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	if !precondition.Allows(user.Version) {
+		return nil, ErrVersionMismatch
+	}
+	return s.replaceUser(ctx, user, req, precondition)
+}
 
-	// Email değişikliği varsa kontrol et
-	if req.Email != nil && *req.Email != user.Email {
-		if _, err := s.userRepo.GetByEmail(ctx, *req.Email); err == nil {
-			return nil, errors.New("email already exists")
-		}
-		user.Email = *req.Email
+// UserPatch düzenlenebilir temsili yerinde değiştirir. Patch uygulanamazsa veya
+// sonuç geçersizse dönen hata olduğu gibi client'a iletilir.
+type UserPatch func(doc *model.ReplaceUserRequest) error
+
+func (s *userService) PatchUser(ctx context.Context, id uint, patch UserPatch, precondition *model.Precondition) (*model.UserResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !precondition.Allows(user.Version) {
+		return nil, ErrVersionMismatch
 	}
 
-	// Username değişikliği varsa kontrol et
-	if req.Username != nil && *req.Username != user.Username {
-		if _, err := s.userRepo.GetByUsername(ctx, *req.Username); err == nil {
-			return nil, errors.New("username already exists")
-		}
-		user.Username = *req.Username
+	isActive := user.IsActive
+	doc := &model.ReplaceUserRequest{
+		Username:  user.Username,
+		Email:     user.Email,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Age:       user.Age,
+		IsActive:  &isActive,
+	}
+	if err := patch(doc); err != nil {
+		return nil, err
 	}
+	// Yazma okunan versiyona bağlı olduğu için patch araya giren bir değişikliğe uygulanmaz
+	return s.replaceUser(ctx, user, doc, precondition)
+}
 
-	// Diğer alanları güncelle
-	if req.FirstName != nil {
-		user.FirstName = *req.FirstName
+// replaceUser kullanıcının düzenlenebilir alanlarını req ile değiştirir ve okunduğu versiyon üzerine yazar
+func (s *userService) replaceUser(ctx context.Context, user *model.User, req *model.ReplaceUserRequest, precondition *model.Precondition) (*model.UserResponse, error) {
+	username, err := normalizeUsername(req.Username)
+	if err != nil {
+		return nil, err
 	}
-	if req.LastName != nil {
-		user.LastName = *req.LastName
+	email, err := normalizeEmail(req.Email)
+	if err != nil {
+		return nil, err
 	}
-	if req.Age != nil {
-		user.Age = *req.Age
+
+	// Sadece harf büyüklüğü değiştiyse adres aynıdır, yeniden doğrulama gerekmez
+	emailChanged := !strings.EqualFold(email, user.Email)
+	if emailChanged {
+		user.EmailVerifiedAt = nil
 	}
+
+	// Email ve username tekilliği Update sırasında unique index ile kontrol edilir
+	user.Username = username
+	user.Email = email
+	user.FirstName = req.FirstName
+	user.LastName = req.LastName
+	user.Age = req.Age
 	if req.IsActive != nil {
 		user.IsActive = *req.IsActive
 	}
 
 	if err := s.userRepo.Update(ctx, user); err != nil {
-		return nil, err
+		return nil, staleWriteError(err, precondition)
+	}
+
+	// Yeni e-posta adresinin de doğrulanması gerekir
+	if emailChanged {
+		s.queueVerificationEmail(ctx, user)
 	}
 
 	return s.toUserResponse(user), nil
 }
 
-func (s *userService) DeleteUser(ctx context.Context, id uint) error {
-	return s.userRepo.Delete(ctx, id)
+// normalizeUsername normalize hatasını alan bazlı validation hatasına çevirir
+func normalizeUsername(value string) (string, error) {
+	username, err := normalize.Username(value)
+	if err != nil {
+		return "", apperror.InvalidFields(err.Error(), []apperror.FieldError{
+			{Field: "username", Rule: "format", Message: err.Error()},
+		}, err)
+	}
+	return username, nil
+}
+
+// normalizeEmail normalize hatasını alan bazlı validation hatasına çevirir
+func normalizeEmail(value string) (string, error) {
+	email, err := normalize.Email(value)
+	if err != nil {
+		return "", apperror.InvalidFields(err.Error(), []apperror.FieldError{
+			{Field: "email", Rule: "format", Message: err.Error()},
+		}, err)
+	}
+	return email, nil
+}
+
+func (s *userService) DeleteUser(ctx context.Context, id uint, precondition *model.Precondition) error {
+	if precondition == nil {
+		return s.userRepo.Delete(ctx, id, 0)
+	}
+
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !precondition.Allows(user.Version) {
+		return ErrVersionMismatch
+	}
+	return s.userRepo.Delete(ctx, id, user.Version)
+}
+
+// ErrVersionMismatch If-Match'teki ETag kullanıcının güncel versiyonu değil
+var ErrVersionMismatch = apperror.PreconditionFailed("If-Match does not match the current version of the user")
+
+// staleWriteError okuma ile yazma arasında araya giren bir yazmayı raporlar. Koşullu
+// isteklerde bu bir 412'dir; koşulsuz isteklerde client bir koşul göndermediği için 409 döner.
+func staleWriteError(err error, precondition *model.Precondition) error {
+	if errors.Is(err, repository.ErrStaleVersion) && precondition == nil {
+		return apperror.Wrap(apperror.ErrConflict, "user was modified concurrently, retry the request", err)
+	}
+	return err
 }
 
 func (s *userService) toUserResponse(user *model.User) *model.UserResponse {
 	return &model.UserResponse{
-		ID:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		Age:       user.Age,
-		IsActive:  user.IsActive,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+		ID:              user.ID,
+		Username:        user.Username,
+		Email:           user.Email,
+		FirstName:       user.FirstName,
+		LastName:        user.LastName,
+		Age:             user.Age,
+		IsActive:        user.IsActive,
+		Role:            user.Role,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		MFAEnabled:      user.TOTPEnabledAt != nil,
+		Version:         user.Version,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
 	}
 }
 
@@ -174,56 +444,663 @@ func generateRandomString(length int) string {
 	return hex.EncodeToString(bytes)
 }
 
+// hashToken token'ları veritabanında saklamak için SHA-256 ile hash'ler
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
 // Login method with ban system
 func (s *userService) Login(ctx context.Context, req *model.LoginRequest, ipAddress, userAgent string) (*model.LoginResponse, error) {
+	response, err := s.VerifyLogin(ctx, req, ipAddress, userAgent)
+	if err != nil || response.MFARequired {
+		return response, err
+	}
+	return s.withSession(ctx, response, ipAddress, userAgent)
+}
+
+func (s *userService) VerifyLogin(ctx context.Context, req *model.LoginRequest, ipAddress, userAgent string) (*model.LoginResponse, error) {
+	// Ban kayıtları ve lookup aynı anahtarı kullanır, büyük/küçük harf farkıyla ban atlatılamaz
+	login := normalize.Lookup(req.Username)
+
+	// Kullanıcı ban kontrolünden önce bulunur: email ile yapılan denemeler de username'in
+	// anahtarına sayılır, böylece şifre ve MFA denemeleri aynı eşikleri paylaşır
+	user, lookupErr := s.userRepo.GetUserForLogin(ctx, login)
+	key := login
+	if lookupErr == nil {
+		key = attemptKey(user)
+	}
+
 	// Check if user is banned
-	if ban, err := s.IsUserBanned(ctx, req.Username, ipAddress); err == nil && ban != nil {
-		return nil, errors.New("account temporarily banned due to multiple failed login attempts")
+	if ban, err := s.IsUserBanned(ctx, key, ipAddress); err == nil && ban != nil {
+		// Ban cevabı şifre kontrolü kadar sürsün, yoksa süre farkı ban/kullanıcı bilgisini sızdırır
+		if lookupErr == nil {
+			s.verifyDummyPassword(req.Password, user.Password)
+		} else {
+			s.verifyDummyPassword(req.Password, s.standInHash(ctx, login))
+		}
+		return nil, s.rejectBanned(ctx, ban, key, ipAddress, userAgent)
 	}
 
-	// Get user for login
-	user, err := s.userRepo.GetUserForLogin(ctx, req.Username)
-	if err != nil {
+	if lookupErr != nil {
+		// Kullanıcı yoksa da hash doğrulaması yapılır, cevap süresi kullanıcının varlığını sızdırmasın
+		s.verifyDummyPassword(req.Password, s.standInHash(ctx, login))
+
 		// Record failed attempt
-		s.recordFailedAttempt(ctx, req.Username, ipAddress, userAgent)
-		return nil, errors.New("invalid credentials")
+		s.recordFailedAttempt(ctx, key, ipAddress, userAgent)
+		s.applyBanPolicy(ctx, key, ipAddress, "Multiple failed login attempts")
+		return nil, ErrInvalidCredentials
 	}
 
+	// Verify password
+	// Aktiflik kontrolü şifreden sonra yapılır, böylece pasif hesaplar da aynı süreyi harcar
+	// ve yanlış şifreyle hesabın pasif olduğu öğrenilemez
+	passwordOK := s.verifyPassword(user, req.Password)
+
 	// Check if user is active
-	if !user.IsActive {
-		return nil, errors.New("account is deactivated")
+	if passwordOK && !user.IsActive {
+		if !s.config.HideAccountStatus {
+			return nil, ErrAccountDeactivated
+		}
+		passwordOK = false
 	}
 
-	// Verify password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+	if !passwordOK {
 		// Record failed attempt
-		s.recordFailedAttempt(ctx, req.Username, ipAddress, userAgent)
+		s.recordFailedAttempt(ctx, key, ipAddress, userAgent)
 		
 		// Check if we should ban the user
-		if s.shouldBanUser(ctx, req.Username, ipAddress) {
-			s.banUser(ctx, req.Username, ipAddress, "Multiple failed login attempts")
-		}
+		s.applyBanPolicy(ctx, key, ipAddress, "Multiple failed login attempts")
 		
-		return nil, errors.New("invalid credentials")
+		return nil, ErrInvalidCredentials
+	}
+
+	// E-posta doğrulaması zorunluysa doğrulanmamış hesaplar login olamaz
+	if s.config.RequireEmailVerification && user.EmailVerifiedAt == nil {
+		return nil, ErrEmailNotVerified
+	}
+
+	// Hash eski bir algoritma veya cost ile üretildiyse düz şifre elimizdeyken yenile
+	s.upgradePasswordHash(ctx, user, req.Password)
+
+	// 2FA açıksa session yerine kısa ömürlü bir MFA challenge token'ı döner
+	if user.TOTPEnabledAt != nil {
+		mfaToken, err := s.createUserToken(ctx, user.ID, model.TokenPurposeMFAChallenge, s.config.MFAChallengeTTL)
+		if err != nil {
+			return nil, err
+		}
+		return &model.LoginResponse{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+		}, nil
 	}
 
 	// Record successful attempt
-	s.recordSuccessfulAttempt(ctx, req.Username, ipAddress, userAgent)
+	s.recordSuccessfulAttempt(ctx, key, ipAddress, userAgent)
+
+	return &model.LoginResponse{User: s.toUserResponse(user)}, nil
+}
+
+// withSession doğrulanmış login için session açar ve token'ı yanıta ekler
+func (s *userService) withSession(ctx context.Context, response *model.LoginResponse, ipAddress, userAgent string) (*model.LoginResponse, error) {
+	token, err := s.createSession(ctx, response.User.ID, ipAddress, userAgent)
+	if err != nil {
+		return nil, err
+	}
+	response.Token = token
+	return response, nil
+}
+
+// CompleteMFALogin login'in ikinci adımı: MFA token'ı ile TOTP veya kurtarma kodunu doğrular.
+// Hatalı kodlar da başarısız login denemesi olarak kaydedilir ve ban sistemine dahil olur.
+func (s *userService) CompleteMFALogin(ctx context.Context, req *model.MFALoginRequest, ipAddress, userAgent string) (*model.LoginResponse, error) {
+	response, err := s.VerifyMFALogin(ctx, req, ipAddress, userAgent)
+	if err != nil {
+		return nil, err
+	}
+	return s.withSession(ctx, response, ipAddress, userAgent)
+}
+
+func (s *userService) VerifyMFALogin(ctx context.Context, req *model.MFALoginRequest, ipAddress, userAgent string) (*model.LoginResponse, error) {
+	challenge, err := s.tokenRepo.GetValid(ctx, model.TokenPurposeMFAChallenge, hashToken(req.MFAToken))
+	if err != nil {
+		return nil, ErrInvalidMFAToken
+	}
+
+	user, err := s.userRepo.GetByID(ctx, challenge.UserID)
+	if err != nil || user.TOTPEnabledAt == nil {
+		return nil, ErrInvalidMFAToken
+	}
+
+	// Login ile aynı anahtar: şifre ve MFA denemeleri aynı eşiklere sayılır
+	key := attemptKey(user)
+	if ban, err := s.IsUserBanned(ctx, key, ipAddress); err == nil && ban != nil {
+		return nil, s.rejectBanned(ctx, ban, key, ipAddress, userAgent)
+	}
+
+	if !s.verifySecondFactor(ctx, user, req) {
+		s.recordFailedAttempt(ctx, key, ipAddress, userAgent)
+		s.applyBanPolicy(ctx, key, ipAddress, "Multiple failed MFA attempts")
+		return nil, ErrInvalidMFACode
+	}
+
+	// Challenge token'ı tek kullanımlık
+	if _, err := s.tokenRepo.Consume(ctx, model.TokenPurposeMFAChallenge, challenge.TokenHash); err != nil {
+		return nil, ErrInvalidMFAToken
+	}
 
-	// Generate simple token (in production, use JWT)
-	token := generateRandomString(32)
+	s.recordSuccessfulAttempt(ctx, key, ipAddress, userAgent)
 
-	return &model.LoginResponse{
-		User:  *s.toUserResponse(user),
-		Token: token,
+	return &model.LoginResponse{User: s.toUserResponse(user)}, nil
+}
+
+func (s *userService) verifySecondFactor(ctx context.Context, user *model.User, req *model.MFALoginRequest) bool {
+	if req.Code != "" {
+		step, ok := totp.Validate(user.TOTPSecret, req.Code, time.Now())
+		if !ok {
+			return false
+		}
+		// Aynı kodun tekrar kullanılmasını engelle
+		advanced, err := s.userRepo.AdvanceTOTPStep(ctx, user.ID, step)
+		return err == nil && advanced
+	}
+	return s.recoveryCodeRepo.Consume(ctx, user.ID, hashRecoveryCode(req.RecoveryCode)) == nil
+}
+
+func (s *userService) EnrollTOTP(ctx context.Context, userID uint) (*model.TOTPEnrollmentResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabledAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.UpdateTOTP(ctx, user.ID, secret, nil); err != nil {
+		return nil, err
+	}
+
+	uri := totp.URI(s.config.MFAIssuer, user.Username, secret)
+	png, err := totp.QRCodePNG(uri, 256)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TOTPEnrollmentResponse{
+		Secret:     secret,
+		OTPAuthURI: uri,
+		QRCodePNG:  png,
 	}, nil
 }
 
+func (s *userService) TOTPQRCode(ctx context.Context, userID uint) ([]byte, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabledAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrNoPendingMFA
+	}
+	return totp.QRCodePNG(totp.URI(s.config.MFAIssuer, user.Username, user.TOTPSecret), 256)
+}
+
+func (s *userService) ConfirmTOTP(ctx context.Context, userID uint, code string) ([]string, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabledAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrNoPendingMFA
+	}
+
+	step, ok := totp.Validate(user.TOTPSecret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	now := time.Now()
+	if err := s.userRepo.UpdateTOTP(ctx, user.ID, user.TOTPSecret, &now); err != nil {
+		return nil, err
+	}
+	if _, err := s.userRepo.AdvanceTOTPStep(ctx, user.ID, step); err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code
+		hashes[i] = hashRecoveryCode(code)
+	}
+	if err := s.recoveryCodeRepo.ReplaceForUser(ctx, user.ID, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func (s *userService) DisableTOTP(ctx context.Context, userID uint, password string) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.TOTPEnabledAt == nil {
+		return ErrMFANotEnabled
+	}
+
+	if !s.verifyPassword(user, password) {
+		return ErrIncorrectPassword
+	}
+
+	if err := s.userRepo.UpdateTOTP(ctx, user.ID, "", nil); err != nil {
+		return err
+	}
+	return s.recoveryCodeRepo.DeleteForUser(ctx, user.ID)
+}
+
+// generateRecoveryCode "xxxxx-xxxxx" formatında 50 bitlik bir kod üretir
+func generateRecoveryCode() (string, error) {
+	bytes := make([]byte, 10)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(bytes))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+// hashRecoveryCode kullanıcının girdiği kodu normalize edip hash'ler
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	return hashToken(normalized)
+}
+
+func (s *userService) Authenticate(ctx context.Context, token string) (*model.User, *model.Session, error) {
+	session, err := s.sessionRepo.GetActiveByTokenHash(ctx, hashToken(token))
+	if err != nil {
+		return nil, nil, ErrInvalidToken
+	}
+
+	user, err := s.userRepo.GetByID(ctx, session.UserID)
+	if err != nil || !user.IsActive {
+		return nil, nil, ErrInvalidToken
+	}
+
+	return user, session, nil
+}
+
+func (s *userService) ChangePassword(ctx context.Context, userID, sessionID uint, req *model.ChangePasswordRequest, ipAddress, userAgent string) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	// Ele geçirilmiş bir session ile mevcut şifre tahmin edilemesin: denemeler Login ile
+	// aynı anahtara ve eşiklere sayılır, ban sürerken şifre hiç kontrol edilmez
+	key := attemptKey(user)
+	if ban, err := s.IsUserBanned(ctx, key, ipAddress); err == nil && ban != nil {
+		return s.rejectBanned(ctx, ban, key, ipAddress, userAgent)
+	}
+
+	if !s.verifyPassword(user, req.CurrentPassword) {
+		s.recordFailedAttempt(ctx, key, ipAddress, userAgent)
+		s.applyBanPolicy(ctx, key, ipAddress, "Multiple failed password change attempts")
+		return ErrIncorrectPassword
+	}
+
+	if err := s.config.PasswordPolicy.Validate(req.NewPassword, user.Username, user.Email); err != nil {
+		return err
+	}
+
+	hashedPassword, err := s.config.PasswordHasher.Hash(req.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.UpdatePassword(ctx, user.ID, hashedPassword); err != nil {
+		return err
+	}
+
+	// Mevcut session hariç diğer tüm session'ları kapat
+	return s.sessionRepo.RevokeAllForUser(ctx, user.ID, sessionID)
+}
+
+// ForgotPassword bilinmeyen veya pasif hesaplar için de hata dönmez. Lookup, token yazımı ve
+// gönderim mail kuyruğunda yapılır, böylece ne yanıt ne de süresi e-postanın kayıtlı olup
+// olmadığını açığa çıkarır. Aynı adres veya IP için çok sık istekler ErrMailRateLimited alır.
+func (s *userService) ForgotPassword(ctx context.Context, email, ipAddress string) error {
+	if err := s.allowMail("password_reset", email, ipAddress); err != nil {
+		return err
+	}
+	return s.enqueueMail(ctx, "password_reset_email", func(ctx context.Context) error {
+		return s.sendPasswordResetEmail(ctx, email)
+	})
+}
+
+func (s *userService) sendPasswordResetEmail(ctx context.Context, email string) error {
+	user, err := s.userRepo.GetByEmail(ctx, normalize.Lookup(email))
+	if err != nil || !user.IsActive {
+		return nil
+	}
+
+	// Önceki sıfırlama linklerini geçersiz kıl
+	if err := s.tokenRepo.InvalidateForUser(ctx, user.ID, model.TokenPurposePasswordReset); err != nil {
+		return err
+	}
+
+	token, err := s.createUserToken(ctx, user.ID, model.TokenPurposePasswordReset, s.config.PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to reset your password. It expires in %s and can only be used once.\n\n%s\n\nIf you did not request a password reset, you can ignore this email.\n",
+			user.Username, s.config.PasswordResetTTL, withToken(s.config.PasswordResetURL, token)),
+	})
+}
+
+func (s *userService) ResetPassword(ctx context.Context, req *model.ResetPasswordRequest) (*model.User, error) {
+	token, err := s.tokenRepo.GetValid(ctx, model.TokenPurposePasswordReset, hashToken(req.Token))
+	if err != nil {
+		return nil, ErrInvalidResetToken
+	}
+
+	user, err := s.userRepo.GetByID(ctx, token.UserID)
+	if err != nil {
+		return nil, ErrInvalidResetToken
+	}
+
+	// Politikaya uymayan şifre token'ı harcamasın diye consume'dan önce kontrol edilir
+	if err := s.config.PasswordPolicy.Validate(req.NewPassword, user.Username, user.Email); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.tokenRepo.Consume(ctx, model.TokenPurposePasswordReset, token.TokenHash); err != nil {
+		return nil, ErrInvalidResetToken
+	}
+
+	hashedPassword, err := s.config.PasswordHasher.Hash(req.NewPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.UpdatePassword(ctx, user.ID, hashedPassword); err != nil {
+		return nil, err
+	}
+
+	if err := s.tokenRepo.InvalidateForUser(ctx, user.ID, model.TokenPurposePasswordReset); err != nil {
+		return nil, err
+	}
+
+	// Şifre sıfırlandığında tüm session'lar kapatılır
+	if err := s.sessionRepo.RevokeAllForUser(ctx, user.ID, 0); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *userService) VerifyEmail(ctx context.Context, token string) (*model.User, error) {
+	userToken, err := s.tokenRepo.Consume(ctx, model.TokenPurposeEmailVerification, hashToken(token))
+	if err != nil {
+		return nil, ErrInvalidVerifyToken
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userToken.UserID)
+	if err != nil {
+		return nil, ErrInvalidVerifyToken
+	}
+
+	if user.EmailVerifiedAt == nil {
+		now := time.Now()
+		if err := s.userRepo.MarkEmailVerified(ctx, user.ID, now); err != nil {
+			return nil, err
+		}
+		user.EmailVerifiedAt = &now
+	}
+
+	return user, nil
+}
+
+// ResendVerificationEmail de ForgotPassword gibi kuyrukta çalışır ve e-postanın kayıtlı olup
+// olmadığını açığa çıkarmaz. Kullanıcı başına throttle sadece log'a düşer.
+func (s *userService) ResendVerificationEmail(ctx context.Context, email, ipAddress string) error {
+	if err := s.allowMail("verification", email, ipAddress); err != nil {
+		return err
+	}
+	return s.enqueueMail(ctx, "verification_email_resend", func(ctx context.Context) error {
+		user, err := s.userRepo.GetByEmail(ctx, normalize.Lookup(email))
+		if err != nil || user.EmailVerifiedAt != nil {
+			return nil
+		}
+
+		if last, err := s.tokenRepo.GetLatestForUser(ctx, user.ID, model.TokenPurposeEmailVerification); err == nil {
+			if time.Since(last.CreatedAt) < s.config.EmailVerificationResendInterval {
+				return ErrResendThrottled
+			}
+		}
+
+		return s.sendVerificationEmail(ctx, user)
+	})
+}
+
+// queueVerificationEmail doğrulama e-postasını mail kuyruğuna bırakır, kuyruğa alınamazsa loglar
+func (s *userService) queueVerificationEmail(ctx context.Context, user *model.User) {
+	recipient := *user
+	err := s.enqueueMail(ctx, "verification_email", func(ctx context.Context) error {
+		return s.sendVerificationEmail(ctx, &recipient)
+	})
+	if err != nil {
+		logger.Logger.Warn("Failed to queue verification email",
+			logger.UserID(user.ID),
+			logger.Error(err),
+		)
+	}
+}
+
+func (s *userService) sendVerificationEmail(ctx context.Context, user *model.User) error {
+	if err := s.tokenRepo.InvalidateForUser(ctx, user.ID, model.TokenPurposeEmailVerification); err != nil {
+		return err
+	}
+
+	token, err := s.createUserToken(ctx, user.ID, model.TokenPurposeEmailVerification, s.config.EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below. It expires in %s.\n\n%s/verify-email?token=%s\n",
+			user.Username, s.config.EmailVerificationTTL, s.config.AppBaseURL, token),
+	})
+}
+
+// allowMail IP ve adres başına sınırları uygular. Sınır adresin kayıtlı olup olmamasından
+// bağımsız sayılır, bu yüzden 429 da hesap varlığını açığa çıkarmaz.
+func (s *userService) allowMail(purpose, email, ipAddress string) error {
+	now := time.Now()
+	if ok, retryAt := s.mailByIP.Allow(ipAddress, now); !ok {
+		return &RateLimitError{RetryAt: retryAt}
+	}
+	if ok, retryAt := s.mailByEmail.Allow(purpose+":"+normalize.Lookup(email), now); !ok {
+		return &RateLimitError{RetryAt: retryAt}
+	}
+	return nil
+}
+
+// enqueueMail işi mail kuyruğuna bırakır. Kuyruk yapılandırılmamışsa (testler) iş
+// isteğin context'iyle senkron çalışır.
+func (s *userService) enqueueMail(ctx context.Context, task string, fn func(ctx context.Context) error) error {
+	if s.config.MailQueue == nil {
+		return fn(ctx)
+	}
+	return s.config.MailQueue.Enqueue(task, fn)
+}
+
+// withToken link'e token query parametresini ekler, link'in mevcut parametreleri korunur
+func withToken(link, token string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return link + "?token=" + url.QueryEscape(token)
+	}
+	query := u.Query()
+	query.Set("token", token)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// createUserToken tek kullanımlık bir token oluşturur ve ham değerini döner
+func (s *userService) createUserToken(ctx context.Context, userID uint, purpose string, ttl time.Duration) (string, error) {
+	token := generateRandomString(64)
+	userToken := &model.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := s.tokenRepo.Create(ctx, userToken); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// verifyPassword şifreyi kullanıcının saklanan hash'iyle karşılaştırır
+func (s *userService) verifyPassword(user *model.User, password string) bool {
+	ok, err := s.config.PasswordHasher.Verify(password, user.Password)
+	return err == nil && ok
+}
+
+// verifyDummyPassword sonucu kullanılmayan bir doğrulamayı standIn hash'inin algoritması ve
+// parametreleriyle yapar. Süre, yerine geçtiği hash'in gerçek doğrulamasıyla aynı olur;
+// güncel hasher'a sabitlenmiş bir dummy, hâlâ eski algoritmada olan hesapları ele verirdi.
+func (s *userService) verifyDummyPassword(password, standIn string) {
+	s.config.PasswordHasher.Verify(password, standIn)
+}
+
+// standInHash kullanıcısı olmayan bir login için doğrulanacak hash'i seçer: login'e göre
+// sabit seçilen gerçek bir hesabın hash'i. Böylece bilinmeyen isimler, karışık algoritmalı
+// gerçek hesaplarla aynı süre dağılımına sahip olur. Tablo boşsa güncel hasher'ın dummy'si.
+func (s *userService) standInHash(ctx context.Context, login string) string {
+	seed := fnv.New32a()
+	seed.Write([]byte(login))
+	if hash, err := s.userRepo.SamplePasswordHash(ctx, seed.Sum32()); err == nil && hash != "" {
+		return hash
+	}
+
+	s.dummyHashOnce.Do(func() {
+		s.dummyHash, _ = s.config.PasswordHasher.Hash(generateRandomString(16))
+	})
+	return s.dummyHash
+}
+
+// upgradePasswordHash başarılı login sonrası hash'i güncel algoritma ve parametrelere taşır.
+// Hata login'i engellemez, bir sonraki login'de tekrar denenir.
+func (s *userService) upgradePasswordHash(ctx context.Context, user *model.User, password string) {
+	hasher := s.config.PasswordHasher
+	if !hasher.NeedsRehash(user.Password) {
+		return
+	}
+
+	previous := hasher.Algorithm(user.Password)
+	hashedPassword, err := hasher.Hash(password)
+	if err == nil {
+		err = s.userRepo.UpdatePassword(ctx, user.ID, hashedPassword)
+	}
+	if err != nil {
+		logger.Logger.Warn("Failed to upgrade password hash",
+			logger.UserID(user.ID),
+			logger.Error(err),
+		)
+		return
+	}
+
+	user.Password = hashedPassword
+	logger.Logger.Info("Password hash upgraded",
+		logger.SecurityEvent("password_rehashed"),
+		logger.UserID(user.ID),
+		logger.String("from_algorithm", previous),
+		logger.String("to_algorithm", hasher.Algorithm(hashedPassword)),
+	)
+}
+
+// createSession yeni bir session oluşturur ve client'a verilecek ham token'ı döner
+func (s *userService) createSession(ctx context.Context, userID uint, ipAddress, userAgent string) (string, error) {
+	token := generateRandomString(64)
+	session := &model.Session{
+		UserID:    userID,
+		TokenHash: hashToken(token),
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		ExpiresAt: time.Now().Add(s.config.SessionTTL),
+	}
+	if err := s.sessionRepo.Create(ctx, session); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// attemptKey bulunan bir kullanıcının login denemeleri ve banları için kanonik anahtarı:
+// hangi login girdisiyle (username veya email) gelinirse gelinsin normalize edilmiş username
+func attemptKey(user *model.User) string {
+	return normalize.Lookup(user.Username)
+}
+
 func (s *userService) IsUserBanned(ctx context.Context, username, ipAddress string) (*model.BanRecord, error) {
 	return s.userRepo.IsBanned(ctx, username, ipAddress)
 }
 
 // Helper methods for ban system
+
+// rejectBanned ban sırasındaki denemeyi kaydeder, ayarlıysa banı uzatır ve bitiş zamanıyla BanError döner
+func (s *userService) rejectBanned(ctx context.Context, ban *model.BanRecord, username, ipAddress, userAgent string) error {
+	s.userRepo.RecordLoginAttempt(ctx, &model.LoginAttempt{
+		Username:  username,
+		IPAddress: ipAddress,
+		Success:   false,
+		Blocked:   true,
+		Timestamp: time.Now(),
+		UserAgent: userAgent,
+	})
+
+	expiresAt := ban.ExpiresAt
+	if s.config.BanPolicy.ExtendOnAttempt {
+		extended := time.Now().Add(ban.ExpiresAt.Sub(ban.BannedAt))
+		if extended.After(expiresAt) {
+			if err := s.userRepo.ExtendBan(ctx, ban.ID, extended); err != nil {
+				logger.Logger.Error("Failed to extend ban", logger.Uint("ban_id", ban.ID), logger.Error(err))
+			} else {
+				expiresAt = extended
+				logger.Logger.Warn("Ban extended",
+					logger.SecurityEvent("ban_extended"),
+					logger.Uint("ban_id", ban.ID),
+					logger.String("ban_type", ban.Type),
+					logger.String("username", username),
+					logger.String("ip", ipAddress),
+					logger.Time("expires_at", expiresAt),
+				)
+			}
+		}
+	}
+
+	return &BanError{ExpiresAt: expiresAt}
+}
+
 func (s *userService) recordFailedAttempt(ctx context.Context, username, ipAddress, userAgent string) {
 	attempt := &model.LoginAttempt{
 		Username:  username,
@@ -246,25 +1123,70 @@ func (s *userService) recordSuccessfulAttempt(ctx context.Context, username, ipA
 	s.userRepo.RecordLoginAttempt(ctx, attempt)
 }
 
-func (s *userService) shouldBanUser(ctx context.Context, username, ipAddress string) bool {
-	// Check failed attempts in the last 2 minutes
-	since := time.Now().Add(-2 * time.Minute)
-	failedAttempts, err := s.userRepo.GetFailedAttempts(ctx, username, ipAddress, since)
-	if err != nil {
-		return false
+// banTypes applyBanPolicy'nin kontrol ettiği anahtarlar
+var banTypes = []string{model.BanTypeUsernameIP, model.BanTypeIP, model.BanTypeUsername}
+
+// applyBanPolicy (username, ip), ip ve username eşiklerini birbirinden bağımsız kontrol eder
+func (s *userService) applyBanPolicy(ctx context.Context, username, ipAddress, reason string) {
+	policy := s.config.BanPolicy
+	now := time.Now()
+
+	for _, banType := range banTypes {
+		threshold := policy.Threshold(banType)
+		if !threshold.Enabled() {
+			continue
+		}
+		since := now.Add(-threshold.Window)
+		count, err := s.userRepo.CountFailedAttempts(ctx, banType, username, ipAddress, since)
+		if err != nil || count < int64(threshold.MaxAttempts) {
+			continue
+		}
+
+		ban := &model.BanRecord{Type: banType, AttemptsSince: &since}
+		if banType != model.BanTypeIP {
+			ban.Username = username
+		}
+		if banType != model.BanTypeUsername {
+			ban.IPAddress = ipAddress
+		}
+		s.banUser(ctx, ban, count, reason)
 	}
-	
-	// Ban if 3 or more failed attempts
-	return len(failedAttempts) >= 3
 }
 
-func (s *userService) banUser(ctx context.Context, username, ipAddress, reason string) {
-	ban := &model.BanRecord{
-		Username:  username,
-		IPAddress: ipAddress,
-		BannedAt:  time.Now(),
-		ExpiresAt: time.Now().Add(2 * time.Minute), // 2 minute ban
-		Reason:    reason,
+// banUser aynı anahtarın EscalationWindow içindeki önceki banlarına göre artan süreli ban uygular
+func (s *userService) banUser(ctx context.Context, ban *model.BanRecord, attempts int64, reason string) {
+	policy := s.config.BanPolicy
+	now := time.Now()
+
+	previousBans, err := s.userRepo.CountBans(ctx, ban.Type, ban.Username, ban.IPAddress, now.Add(-policy.EscalationWindow))
+	if err != nil {
+		previousBans = 0
+	}
+	duration := policy.Duration(previousBans)
+
+	fields := []zap.Field{
+		logger.String("ban_type", ban.Type),
+		logger.String("username", ban.Username),
+		logger.String("ip", ban.IPAddress),
+		logger.Int64("failed_attempts", attempts),
+		logger.Int64("previous_bans", previousBans),
+		logger.Duration("ban_duration", duration),
+		logger.String("reason", reason),
+		logger.Bool("dry_run", policy.DryRun),
 	}
-	s.userRepo.RecordBan(ctx, ban)
+
+	if policy.DryRun {
+		logger.Logger.Warn("Ban skipped (dry run)", append(fields, logger.SecurityEvent("ban_dry_run"))...)
+		return
+	}
+
+	ban.BannedAt = now
+	ban.ExpiresAt = now.Add(duration)
+	ban.Reason = reason
+	if err := s.userRepo.RecordBan(ctx, ban); err != nil {
+		logger.Logger.Error("Failed to record ban", append(fields, logger.Error(err))...)
+		return
+	}
+
+	logger.Logger.Warn("Ban applied", append(fields, logger.SecurityEvent("ban_applied"))...)
 }
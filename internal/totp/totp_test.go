@@ -0,0 +1,110 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret RFC 4226 ve RFC 6238 test vektörlerindeki SHA-1 anahtarı ("12345678901234567890")
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestHOTP(t *testing.T) {
+	// RFC 4226 Appendix D
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+
+	key, err := decodeSecret(rfcSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for counter, code := range want {
+		if got := hotp(key, int64(counter)); got != code {
+			t.Errorf("hotp(%d) = %s, want %s", counter, got, code)
+		}
+	}
+}
+
+func TestCode(t *testing.T) {
+	// RFC 6238 Appendix B, SHA-1; 8 haneli kodların son 6 hanesi
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		got, err := Code(rfcSecret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatalf("Code(%d): %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("Code(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	// 1111111109 adım 37037036'nın 29. saniyesi
+	now := time.Unix(1111111109, 0)
+	step := Step(now)
+
+	tests := []struct {
+		name   string
+		secret string
+		codeAt time.Time
+		// code boşsa codeAt anındaki kod kullanılır
+		code     string
+		wantStep int64
+		wantOK   bool
+	}{
+		{name: "current step", secret: rfcSecret, codeAt: now, wantStep: step, wantOK: true},
+		{name: "previous step within skew", secret: rfcSecret, codeAt: now.Add(-Period * time.Second), wantStep: step - 1, wantOK: true},
+		{name: "next step within skew", secret: rfcSecret, codeAt: now.Add(Period * time.Second), wantStep: step + 1, wantOK: true},
+		{name: "two steps behind", secret: rfcSecret, codeAt: now.Add(-2 * Period * time.Second)},
+		{name: "two steps ahead", secret: rfcSecret, codeAt: now.Add(2 * Period * time.Second)},
+		{name: "spaces are ignored", secret: rfcSecret, code: " 081 804 ", wantStep: step, wantOK: true},
+		{name: "wrong code", secret: rfcSecret, code: "081805"},
+		{name: "too short", secret: rfcSecret, code: "81804"},
+		{name: "eight digit code", secret: rfcSecret, code: "07081804"},
+		{name: "lowercase padded secret", secret: strings.ToLower(rfcSecret) + "====", code: "081804", wantStep: step, wantOK: true},
+		{name: "invalid secret", secret: "not base32!", code: "081804"},
+		{name: "empty secret", secret: "", code: "081804"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code := tt.code
+			if code == "" {
+				var err error
+				if code, err = Code(rfcSecret, tt.codeAt); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			gotStep, ok := Validate(tt.secret, code, now)
+			if ok != tt.wantOK || gotStep != tt.wantStep {
+				t.Errorf("Validate(%q) = %d, %v; want %d, %v", code, gotStep, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := decodeSecret(secret)
+	if err != nil {
+		t.Fatalf("decodeSecret(%q): %v", secret, err)
+	}
+	if len(key) != 20 {
+		t.Errorf("secret is %d bytes, want 20", len(key))
+	}
+}